@@ -0,0 +1,90 @@
+package streamdeck
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	"golang.org/x/image/draw"
+)
+
+// Atlas is a sprite sheet: a single image containing many icons, which are
+// addressed by name.
+type Atlas struct {
+	img   image.Image
+	icons map[string]image.Rectangle
+}
+
+// atlasEntry is the position of an icon in an atlas index file.
+type atlasEntry struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// LoadAtlas loads an atlas from an image and a JSON index, which maps icon
+// names to their position in the image:
+//
+//	{"home": {"x": 0, "y": 0, "width": 96, "height": 96}}
+func LoadAtlas(imagePath, indexPath string) (*Atlas, error) {
+	f, err := os.Open(imagePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() //nolint:errcheck // r/o file
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode atlas image: %v", err)
+	}
+
+	b, err := ioutil.ReadFile(indexPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var index map[string]atlasEntry
+	if err := json.Unmarshal(b, &index); err != nil {
+		return nil, fmt.Errorf("cannot parse atlas index: %v", err)
+	}
+
+	a := &Atlas{
+		img:   img,
+		icons: make(map[string]image.Rectangle, len(index)),
+	}
+	for name, e := range index {
+		r := image.Rect(e.X, e.Y, e.X+e.Width, e.Y+e.Height).Add(img.Bounds().Min)
+		if r.Empty() || !r.In(img.Bounds()) {
+			return nil, fmt.Errorf("icon %s is outside of the atlas image", name)
+		}
+		a.icons[name] = r
+	}
+
+	return a, nil
+}
+
+// Icon returns the icon with the given name.
+func (a *Atlas) Icon(name string) (image.Image, error) {
+	r, ok := a.icons[name]
+	if !ok {
+		return nil, fmt.Errorf("no icon named %s in atlas", name)
+	}
+
+	icon := image.NewRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	draw.Copy(icon, image.Point{}, a.img, r, draw.Src, nil)
+	return icon, nil
+}
+
+// Names returns the names of all icons in the atlas, sorted alphabetically.
+func (a *Atlas) Names() []string {
+	names := make([]string, 0, len(a.icons))
+	for name := range a.icons {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
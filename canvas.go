@@ -0,0 +1,22 @@
+package streamdeck
+
+import (
+	"image"
+
+	"golang.org/x/image/draw"
+)
+
+// Canvas returns a drawable image in the resolution of the device for the
+// button with the given index, initialized with the button's current image.
+// Calling flush sends the drawn image to the device, so callers can draw with
+// the standard library and flush whenever they are done.
+func (d Device) Canvas(index uint8) (canvas draw.Image, flush func() error) {
+	img := image.NewRGBA(image.Rect(0, 0, int(d.Pixels), int(d.Pixels)))
+	if current := d.Image(index); current != nil {
+		draw.Copy(img, image.Point{}, current, current.Bounds(), draw.Src, nil)
+	}
+
+	return img, func() error {
+		return d.SetImage(index, img)
+	}
+}
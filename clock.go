@@ -0,0 +1,213 @@
+package streamdeck
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Clock shows the current time in large digits spanning a row of buttons, or
+// on a single button. It updates on second boundaries and stops updating
+// while the device is asleep.
+type Clock struct {
+	d      *Device
+	keys   []uint8
+	layout string
+	remove []func()
+
+	mu    sync.Mutex
+	shown string
+	stop  chan struct{}
+}
+
+// NewClock creates a clock showing the current time across the given
+// buttons, formatted with the given time layout, e.g. "15:04" or
+// time.Kitchen. An empty layout uses the theme's TimeLayout. Key events of the
+// clock's buttons are still emitted.
+func (d *Device) NewClock(keys []uint8, layout string) (*Clock, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("clock needs at least one key")
+	}
+
+	c := &Clock{
+		d:      d,
+		keys:   keys,
+		layout: layout,
+	}
+	if err := c.render(); err != nil {
+		return nil, err
+	}
+
+	// register the sleep hook before starting, so a sleep in between can't be
+	// missed
+	c.remove = []func(){
+		d.onThemeChange(c.render),
+		d.onSleepStateChange(c.sleepStateChanged),
+	}
+
+	c.mu.Lock()
+	if !d.Asleep() {
+		c.startLocked()
+	}
+	c.mu.Unlock()
+	return c, nil
+}
+
+// Close stops updating the clock. The buttons keep their images until they
+// get replaced.
+func (c *Clock) Close() {
+	for _, remove := range c.remove {
+		remove()
+	}
+
+	c.mu.Lock()
+	c.stopLocked()
+	c.mu.Unlock()
+}
+
+func (c *Clock) startLocked() {
+	c.stopLocked()
+	stop := make(chan struct{})
+	c.stop = stop
+	go tickSeconds(time.Now().Truncate(time.Second), stop, func() {
+		c.tick(stop)
+	})
+}
+
+func (c *Clock) stopLocked() {
+	if c.stop == nil {
+		return
+	}
+	close(c.stop)
+	c.stop = nil
+}
+
+// sleepStateChanged stops updating the clock while the device is asleep, and
+// catches up on waking up.
+func (c *Clock) sleepStateChanged(asleep bool) {
+	c.mu.Lock()
+	if asleep {
+		c.stopLocked()
+		c.mu.Unlock()
+		return
+	}
+	c.startLocked()
+	c.mu.Unlock()
+
+	_ = c.render()
+}
+
+// tick renders the clock if the displayed time changed. It does nothing once
+// the ticker got stopped, e.g. because the device fell asleep in the
+// meantime.
+func (c *Clock) tick(stop chan struct{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stop != stop {
+		return
+	}
+	if c.format(time.Now(), c.d.Theme()) != c.shown {
+		_ = c.renderLocked()
+	}
+}
+
+// render shows the current time across the clock's buttons.
+func (c *Clock) render() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.renderLocked()
+}
+
+func (c *Clock) renderLocked() error {
+	theme := c.d.Theme()
+	c.shown = c.format(time.Now(), theme)
+
+	return c.d.setTextAcross(c.keys, c.shown, theme)
+}
+
+// format formats the given time with the clock's layout, or the theme's if
+// the clock has none.
+func (c *Clock) format(now time.Time, theme Theme) string {
+	if c.layout == "" {
+		return theme.formatTime(now)
+	}
+	return now.Format(c.layout)
+}
+
+// Stopwatch shows the elapsed time in large digits spanning a row of buttons.
+// It updates on the stopwatch's second boundaries and stops updating while the
+// device is asleep, but keeps measuring the time.
+type Stopwatch struct {
+	timer
+}
+
+// NewStopwatch creates a stopwatch showing the elapsed time across the given
+// buttons. The startPause button starts and pauses the stopwatch, the reset
+// button stops it and resets the elapsed time. Key events of the stopwatch's
+// buttons are consumed and not emitted, until it gets closed.
+func (d *Device) NewStopwatch(keys []uint8, startPause, reset uint8) (*Stopwatch, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("stopwatch needs at least one key")
+	}
+
+	s := &Stopwatch{}
+	s.timer = timer{
+		d:           d,
+		keys:        keys,
+		startPause:  startPause,
+		reset:       reset,
+		renderState: s.renderLocked,
+	}
+	if err := s.render(); err != nil {
+		return nil, err
+	}
+
+	s.register()
+	return s, nil
+}
+
+// Elapsed returns the elapsed time.
+func (s *Stopwatch) Elapsed() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.elapsedLocked()
+}
+
+func (s *Stopwatch) renderLocked() error {
+	theme := s.d.Theme()
+	if err := s.d.setTextAcross(s.keys, theme.formatDuration(s.elapsedLocked().Truncate(time.Second)), theme); err != nil {
+		return err
+	}
+	return s.d.setControls(s.startPause, s.reset, s.running, theme)
+}
+
+// tickSeconds calls tick on every second boundary counted from origin, until
+// stop gets closed.
+func tickSeconds(origin time.Time, stop chan struct{}, tick func()) {
+	timer := time.NewTimer(untilNextSecond(origin, time.Now()))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			tick()
+			timer.Reset(untilNextSecond(origin, time.Now()))
+
+		case <-stop:
+			return
+		}
+	}
+}
+
+// untilNextSecond returns the time from now until the next second boundary
+// counted from origin.
+func untilNextSecond(origin, now time.Time) time.Duration {
+	elapsed := now.Sub(origin) % time.Second
+	if elapsed < 0 {
+		elapsed += time.Second
+	}
+	return time.Second - elapsed
+}
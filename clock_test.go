@@ -0,0 +1,53 @@
+package streamdeck
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUntilNextSecond(t *testing.T) {
+	origin := time.Date(2020, 1, 1, 12, 0, 0, 300*int(time.Millisecond), time.UTC)
+
+	tests := []struct {
+		name  string
+		since time.Duration
+		next  time.Duration
+	}{
+		{"at origin", 0, time.Second},
+		{"within first second", 250 * time.Millisecond, 750 * time.Millisecond},
+		{"on a boundary", 3 * time.Second, time.Second},
+		{"just before a boundary", 2*time.Second - time.Millisecond, time.Millisecond},
+		{"before origin", -250 * time.Millisecond, 250 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if next := untilNextSecond(origin, origin.Add(tt.since)); next != tt.next {
+				t.Errorf("expected %v, got %v", tt.next, next)
+			}
+		})
+	}
+}
+
+func TestTickSeconds(t *testing.T) {
+	origin := time.Now().Add(-990 * time.Millisecond)
+	stop := make(chan struct{})
+	ticked := make(chan time.Time, 1)
+
+	go tickSeconds(origin, stop, func() {
+		select {
+		case ticked <- time.Now():
+		default:
+		}
+	})
+	defer close(stop)
+
+	select {
+	case at := <-ticked:
+		if since := at.Sub(origin); since < time.Second {
+			t.Errorf("ticked before the second boundary, after %v", since)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("didn't tick on the second boundary")
+	}
+}
@@ -0,0 +1,14 @@
+package main
+
+import (
+	"os"
+
+	"github.com/muesli/streamdeck"
+)
+
+// beep is a feedback ringing the terminal bell on every key press.
+func beep(k streamdeck.Key) {
+	if k.Pressed {
+		_, _ = os.Stdout.WriteString("\a")
+	}
+}
@@ -1,30 +1,113 @@
 package main
 
 import (
-	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/muesli/coral"
 )
 
 var (
+	fade time.Duration
+
 	brightnessCmd = &coral.Command{
 		Use:   "brightness <percentage>",
 		Short: "controls the brightness of the keys (in percent)",
+		Long: "controls the brightness of the keys (in percent). Prefix the percentage with\n" +
+			"+ or - to adjust the brightness relative to the last one set.",
 		RunE: func(cmd *coral.Command, args []string) error {
 			if len(args) < 1 {
-				return fmt.Errorf("brightness requires a percentage")
+				return invalidArgument("brightness requires a percentage")
 			}
 
-			brightness, err := strconv.ParseInt(args[0], 10, 8)
+			value, err := strconv.ParseInt(args[0], 10, 8)
 			if err != nil {
-				return fmt.Errorf("supplied parameter is not a valid number")
+				return invalidArgument("supplied parameter is not a valid number")
+			}
+
+			current, known := lastBrightness()
+			brightness := value
+			if strings.HasPrefix(args[0], "+") || strings.HasPrefix(args[0], "-") {
+				if !known {
+					return invalidArgument("current brightness is unknown, set an absolute brightness first")
+				}
+				brightness = int64(current) + value
+			} else if brightness > 100 {
+				return invalidArgument("brightness must be between 0 and 100")
 			}
-			return d.SetBrightness(uint8(brightness))
+
+			if brightness < 0 {
+				brightness = 0
+			}
+			if brightness > 100 {
+				brightness = 100
+			}
+
+			if fade > 0 && known {
+				if err := d.Fade(current, uint8(brightness), fade); err != nil {
+					return ioError(err)
+				}
+			}
+			if err := d.SetBrightness(uint8(brightness)); err != nil {
+				return ioError(err)
+			}
+
+			if !dryRun {
+				saveBrightness(d.Brightness())
+			}
+			return nil
 		},
 	}
 )
 
+// brightnessFile returns the path of the file remembering the brightness last
+// set on the device, as the device can't report it.
+func brightnessFile() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "streamdeck-cli", "brightness-"+d.Serial), nil
+}
+
+// lastBrightness returns the brightness last set on the device, and whether
+// it is known.
+func lastBrightness() (uint8, bool) {
+	path, err := brightnessFile()
+	if err != nil {
+		return 0, false
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+	v, err := strconv.ParseUint(strings.TrimSpace(string(b)), 10, 8)
+	if err != nil || v > 100 {
+		return 0, false
+	}
+	return uint8(v), true
+}
+
+// saveBrightness remembers the brightness set on the device. Failing to do so
+// only disables relative adjustments, so errors are ignored.
+func saveBrightness(brightness uint8) {
+	path, err := brightnessFile()
+	if err != nil {
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+	_ = ioutil.WriteFile(path, []byte(strconv.Itoa(int(brightness))+"\n"), 0644)
+}
+
 func init() {
-	RootCmd.AddCommand(brightnessCmd)
+	brightnessCmd.Flags().DurationVar(&fade, "fade", 0, "fade to the new brightness over the given duration, e.g. 500ms")
+	RootCmd.AddCommand(deviceCommand(brightnessCmd))
 }
@@ -9,11 +9,11 @@ var (
 		Use:   "clear",
 		Short: "clears all images",
 		RunE: func(cmd *coral.Command, args []string) error {
-			return d.Clear()
+			return ioError(d.Clear())
 		},
 	}
 )
 
 func init() {
-	RootCmd.AddCommand(clearCmd)
+	RootCmd.AddCommand(deviceCommand(clearCmd))
 }
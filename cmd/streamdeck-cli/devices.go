@@ -1,40 +1,58 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/muesli/coral"
 	"github.com/muesli/streamdeck"
 )
 
 var (
+	watch bool
+
 	devicesCmd = &coral.Command{
 		Use:   "devices",
 		Short: "devices lists all available Stream Deck devices",
 		RunE: func(cmd *coral.Command, args []string) error {
-			_ = d.Close()
+			streamdeck.UnknownDeviceHandler = func(pid uint16, serial string) {
+				logger.Printf("Unsupported Elgato device (product ID: 0x%04x, serial: %s), please report it\n", pid, serial)
+			}
+
+			if watch {
+				return watchDevices()
+			}
 
 			devs, err := streamdeck.Devices()
 			if err != nil {
-				return fmt.Errorf("no Stream Deck devices found: %s", err)
+				return noDevice("no Stream Deck devices found: %s", err)
 			}
 			if len(devs) == 0 {
-				return fmt.Errorf("no Stream Deck devices found")
+				return noDevice("no Stream Deck devices found")
 			}
 
 			fmt.Printf("Found %d devices:\n", len(devs))
 
 			for _, d := range devs {
+				fmt.Printf("%s with %d keys (ID: %s", d, d.Keys, d.ID)
+
 				if err := d.Open(); err != nil {
-					return fmt.Errorf("can't open device %s: %s", d.ID, err)
+					fmt.Println(", busy)")
+					continue
 				}
 
 				ver, err := d.FirmwareVersion()
 				if err != nil {
-					return fmt.Errorf("can't retrieve device info: %s", err)
+					fmt.Print(", firmware unknown")
+				} else {
+					fmt.Printf(", firmware %s", ver)
 				}
-				fmt.Printf("Serial %s with %d keys (ID: %s, firmware %s)\n",
-					d.Serial, d.Keys, d.ID, ver)
+				fmt.Printf(", %s", d.SupportLevel())
+				fmt.Println(")")
 
 				_ = d.Close()
 			}
@@ -44,6 +62,36 @@ var (
 	}
 )
 
+// watchDevices prints attach and detach events until interrupted.
+func watchDevices() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sig
+		cancel()
+	}()
+
+	events, err := streamdeck.WatchDevices(ctx, time.Second)
+	if err != nil {
+		return noDevice("cannot watch Stream Deck devices: %s", err)
+	}
+
+	fmt.Println("Watching for devices, press Ctrl+C to stop...")
+	for e := range events {
+		if e.Attached {
+			fmt.Printf("%s attached: %s with %d keys (ID: %s)\n", time.Now().Format("15:04:05"), e.Device, e.Device.Keys, e.Device.ID)
+		} else {
+			fmt.Printf("%s detached: %s (ID: %s)\n", time.Now().Format("15:04:05"), e.Device, e.Device.ID)
+		}
+	}
+
+	return nil
+}
+
 func init() {
+	devicesCmd.Flags().BoolVarP(&watch, "watch", "w", false, "keep running and print devices as they get attached or detached")
 	RootCmd.AddCommand(devicesCmd)
 }
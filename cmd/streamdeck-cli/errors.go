@@ -0,0 +1,54 @@
+package main
+
+import "fmt"
+
+// Exit codes, so scripts can tell failures apart.
+const (
+	exitError           = 1
+	exitNoDevice        = 2
+	exitDeviceBusy      = 3
+	exitInvalidArgument = 4
+	exitIOError         = 5
+)
+
+// cliError is an error carrying the exit code it should cause.
+type cliError struct {
+	code int
+	err  error
+}
+
+func (e cliError) Error() string {
+	return e.err.Error()
+}
+
+// exitCode returns the exit code for the given error.
+func exitCode(err error) int {
+	if e, ok := err.(cliError); ok {
+		return e.code
+	}
+	return exitError
+}
+
+// noDevice returns an error signaling that no device could be found.
+func noDevice(format string, a ...interface{}) error {
+	return cliError{exitNoDevice, fmt.Errorf(format, a...)}
+}
+
+// deviceBusy returns an error signaling that the device couldn't be opened.
+func deviceBusy(format string, a ...interface{}) error {
+	return cliError{exitDeviceBusy, fmt.Errorf(format, a...)}
+}
+
+// invalidArgument returns an error signaling invalid user input.
+func invalidArgument(format string, a ...interface{}) error {
+	return cliError{exitInvalidArgument, fmt.Errorf(format, a...)}
+}
+
+// ioError marks an error as failed communication with the device, or nil if
+// err is nil.
+func ioError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return cliError{exitIOError, err}
+}
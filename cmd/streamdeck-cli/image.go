@@ -1,7 +1,6 @@
 package main
 
 import (
-	"fmt"
 	"image"
 	"os"
 	"strconv"
@@ -12,6 +11,7 @@ import (
 
 	"github.com/muesli/coral"
 	"github.com/nfnt/resize"
+	_ "golang.org/x/image/webp"
 )
 
 var (
@@ -20,30 +20,30 @@ var (
 		Short: "sets an image on a key",
 		RunE: func(cmd *coral.Command, args []string) error {
 			if len(args) < 2 {
-				return fmt.Errorf("image requires the key-index and an image")
+				return invalidArgument("image requires the key-index and an image")
 			}
 
 			key, err := strconv.ParseInt(args[0], 10, 8)
 			if err != nil {
-				return fmt.Errorf("supplied parameter is not a valid number")
+				return invalidArgument("supplied parameter is not a valid number")
 			}
 
 			f, err := os.Open(args[1])
 			if err != nil {
-				return err
+				return invalidArgument("can't open image: %s", err)
 			}
 			defer f.Close() //nolint:errcheck // r/o file
 
 			img, _, err := image.Decode(f)
 			if err != nil {
-				return err
+				return invalidArgument("can't decode image: %s", err)
 			}
 
-			return d.SetImage(uint8(key), resize.Resize(d.Pixels, d.Pixels, img, resize.Lanczos3))
+			return ioError(d.SetImage(uint8(key), resize.Resize(d.Pixels, d.Pixels, img, resize.Lanczos3)))
 		},
 	}
 )
 
 func init() {
-	RootCmd.AddCommand(imageCmd)
+	RootCmd.AddCommand(deviceCommand(imageCmd))
 }
@@ -1,7 +1,8 @@
 package main
 
 import (
-	"fmt"
+	"io/ioutil"
+	"log"
 	"os"
 
 	"github.com/muesli/coral"
@@ -11,33 +12,58 @@ import (
 var (
 	// RootCmd is the core command used for cli-arg parsing.
 	RootCmd = &coral.Command{
-		Use:                "streamdeck-cli",
-		Short:              "streamdeck-cli lets you control your Elgato Stream Deck",
-		SilenceErrors:      true,
-		SilenceUsage:       true,
-		PersistentPreRunE:  initStreamDeck,
-		PersistentPostRunE: closeStreamDeck,
+		Use:           "streamdeck-cli",
+		Short:         "streamdeck-cli lets you control your Elgato Stream Deck",
+		SilenceErrors: true,
+		SilenceUsage:  true,
 	}
 
 	d streamdeck.Device
+
+	// logger prints error messages and warnings, unless --quiet is set.
+	logger = log.New(os.Stderr, "", 0)
+
+	quiet   bool
+	dryRun  bool
+	verbose bool
 )
 
+// deviceCommand sets up the given command to open the first Stream Deck
+// before it runs, and to close it afterwards. Commands that don't talk to a
+// device work without one being attached.
+func deviceCommand(cmd *coral.Command) *coral.Command {
+	cmd.PreRunE = initStreamDeck
+	cmd.PostRunE = closeStreamDeck
+	return cmd
+}
+
 func closeStreamDeck(cmd *coral.Command, args []string) error {
-	return d.Close()
+	if dryRun {
+		return nil
+	}
+	return ioError(d.Close())
 }
 
 func initStreamDeck(cmd *coral.Command, args []string) error {
 	devs, err := streamdeck.Devices()
 	if err != nil {
-		return fmt.Errorf("no Stream Deck devices found: %s", err)
+		return noDevice("no Stream Deck devices found: %s", err)
 	}
 	if len(devs) == 0 {
-		return fmt.Errorf("no Stream Deck devices found")
+		return noDevice("no Stream Deck devices found")
 	}
 	d = devs[0]
 
+	if dryRun || verbose {
+		d.SetLogger(log.New(os.Stdout, "", 0))
+	}
+	if dryRun {
+		d.SetDryRun(true)
+		return nil
+	}
+
 	if err := d.Open(); err != nil {
-		return fmt.Errorf("can't open device: %s", err)
+		return deviceBusy("can't open device: %s", err)
 	}
 
 	/*
@@ -53,8 +79,29 @@ func initStreamDeck(cmd *coral.Command, args []string) error {
 }
 
 func main() {
+	RootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "don't print error messages and warnings")
+	RootCmd.PersistentFlags().BoolVarP(&dryRun, "dry-run", "n", false, "validate inputs and show what would be written, without writing to the device")
+	RootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "log feature reports and image pages sent to the device")
+	RootCmd.SetFlagErrorFunc(func(cmd *coral.Command, err error) error {
+		return invalidArgument("%s", err)
+	})
+	// commands only run after the command line got parsed successfully
+	var parsed bool
+	RootCmd.PersistentPreRun = func(cmd *coral.Command, args []string) {
+		parsed = true
+		if quiet {
+			logger.SetOutput(ioutil.Discard)
+		}
+	}
+
 	if err := RootCmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, "Error:", err)
-		os.Exit(1)
+		if _, ok := err.(cliError); !ok && !parsed {
+			// unknown commands and arguments
+			err = invalidArgument("%s", err)
+		}
+		if !quiet {
+			logger.Println("Error:", err)
+		}
+		os.Exit(exitCode(err))
 	}
 }
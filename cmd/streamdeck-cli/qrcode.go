@@ -0,0 +1,30 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/muesli/coral"
+)
+
+var (
+	qrcodeCmd = &coral.Command{
+		Use:   "qrcode <key> <content>",
+		Short: "shows a QR code on a key",
+		RunE: func(cmd *coral.Command, args []string) error {
+			if len(args) < 2 {
+				return invalidArgument("qrcode requires the key-index and the content to encode")
+			}
+
+			key, err := strconv.ParseInt(args[0], 10, 8)
+			if err != nil {
+				return invalidArgument("supplied parameter is not a valid number")
+			}
+
+			return ioError(d.SetQRCode(uint8(key), args[1]))
+		},
+	}
+)
+
+func init() {
+	RootCmd.AddCommand(deviceCommand(qrcodeCmd))
+}
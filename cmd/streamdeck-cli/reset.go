@@ -1,19 +1,46 @@
 package main
 
 import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
 	"github.com/muesli/coral"
 )
 
 var (
+	yes bool
+
 	resetCmd = &coral.Command{
 		Use:   "reset",
 		Short: "resets the device, clears all images and shows the default logo",
 		RunE: func(cmd *coral.Command, args []string) error {
-			return d.Reset()
+			if pid, ok := d.LockOwner(); ok && !yes {
+				if !confirm(fmt.Sprintf("The device is managed by another process (PID %d). Reset it anyway?", pid)) {
+					return deviceBusy("device is managed by another process (PID %d), use --yes to reset it anyway", pid)
+				}
+			}
+
+			return ioError(d.Reset())
 		},
 	}
 )
 
+// confirm asks the user the given yes/no question, if stdin is a terminal.
+func confirm(question string) bool {
+	fi, err := os.Stdin.Stat()
+	if err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+
+	fmt.Printf("%s [y/N] ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
 func init() {
-	RootCmd.AddCommand(resetCmd)
+	resetCmd.Flags().BoolVarP(&yes, "yes", "y", false, "reset without confirmation, even if another process manages the device")
+	RootCmd.AddCommand(deviceCommand(resetCmd))
 }
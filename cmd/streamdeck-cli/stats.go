@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"time"
+
+	"github.com/muesli/coral"
+)
+
+var (
+	statsDuration time.Duration
+	showHeatmap   bool
+	statsBeep     bool
+
+	statsCmd = &coral.Command{
+		Use:   "stats",
+		Short: "counts key presses and prints usage statistics",
+		Long: "counts key presses until interrupted or the given duration elapsed, then prints\n" +
+			"how often and when each key was last pressed. The device can't store usage\n" +
+			"statistics, so only presses while this command runs are counted.",
+		RunE: func(cmd *coral.Command, args []string) error {
+			if dryRun {
+				return invalidArgument("stats needs to read from the device and can't run with --dry-run")
+			}
+
+			d.EnableStats(true)
+			if statsBeep {
+				d.SetFeedback(beep)
+			}
+			keys, err := d.ReadKeys()
+			if err != nil {
+				return ioError(err)
+			}
+
+			sig := make(chan os.Signal, 1)
+			signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+			defer signal.Stop(sig)
+
+			var timeout <-chan time.Time
+			if statsDuration > 0 {
+				timeout = time.After(statsDuration)
+			}
+
+			fmt.Println("Counting key presses, press Ctrl+C to stop...")
+		loop:
+			for {
+				select {
+				case _, ok := <-keys:
+					if !ok {
+						break loop
+					}
+					if showHeatmap {
+						if err := d.ShowHeatmap(); err != nil {
+							return ioError(err)
+						}
+					}
+				case <-sig:
+					break loop
+				case <-timeout:
+					break loop
+				}
+			}
+
+			printStats()
+			return nil
+		},
+	}
+)
+
+// printStats prints the collected usage statistics, most pressed keys first.
+func printStats() {
+	stats := d.Stats()
+
+	indices := make([]uint8, 0, len(stats))
+	for i := range stats {
+		indices = append(indices, i)
+	}
+	sort.Slice(indices, func(a, b int) bool {
+		if stats[indices[a]].Presses != stats[indices[b]].Presses {
+			return stats[indices[a]].Presses > stats[indices[b]].Presses
+		}
+		return indices[a] < indices[b]
+	})
+
+	if len(indices) == 0 {
+		fmt.Println("No keys pressed.")
+		return
+	}
+	for _, i := range indices {
+		fmt.Printf("Key %2d: %d presses, last at %s\n", i, stats[i].Presses, stats[i].LastPress.Format("15:04:05"))
+	}
+}
+
+func init() {
+	statsCmd.Flags().DurationVarP(&statsDuration, "duration", "d", 0, "stop counting after the given duration, e.g. 10m")
+	statsCmd.Flags().BoolVar(&showHeatmap, "heatmap", false, "show a heatmap of the key usage on the device while counting")
+	statsCmd.Flags().BoolVar(&statsBeep, "beep", false, "ring the terminal bell on every key press")
+	RootCmd.AddCommand(deviceCommand(statsCmd))
+}
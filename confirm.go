@@ -0,0 +1,167 @@
+package streamdeck
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"sync"
+	"time"
+
+	"golang.org/x/image/draw"
+)
+
+// ConfirmButton is a button bound to a destructive action with
+// HoldToConfirm.
+type ConfirmButton struct {
+	remove func()
+
+	mu     sync.Mutex
+	cancel chan struct{}
+	closed bool
+}
+
+// HoldToConfirm sets an image on a button and binds a destructive action to
+// it: the action only fires after the button has been held down for the given
+// duration. While the button is held, a progress ring is drawn around the
+// image. Releasing the button early cancels the action. Key events of the
+// button are consumed and not emitted, until the binding gets closed.
+func (d *Device) HoldToConfirm(index uint8, img image.Image, hold time.Duration, action func()) (*ConfirmButton, error) {
+	if err := d.SetImage(index, img); err != nil {
+		return nil, err
+	}
+
+	c := &ConfirmButton{}
+	c.remove = d.Use(func(k Key) (Key, bool) {
+		if k.Index != index {
+			return k, true
+		}
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		if c.closed {
+			return k, true
+		}
+		if k.Pressed {
+			if c.cancel != nil {
+				// already held down, e.g. after a missed release
+				return k, false
+			}
+			cancel := make(chan struct{})
+			c.cancel = cancel
+			go d.confirm(index, hold, cancel, func() {
+				if c.confirmed(cancel) {
+					action()
+				}
+			})
+		} else {
+			c.cancelLocked()
+		}
+		return k, false
+	})
+
+	return c, nil
+}
+
+// Close removes the binding, so key events of its button get emitted again. A
+// confirmation in progress gets cancelled, so its action doesn't fire. The
+// button keeps its image until it gets replaced.
+func (c *ConfirmButton) Close() {
+	c.remove()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closed = true
+	c.cancelLocked()
+}
+
+// confirmed returns true if the confirmation with the given cancel channel is
+// still in progress, i.e. neither released nor closed.
+func (c *ConfirmButton) confirmed(cancel chan struct{}) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return !c.closed && c.cancel == cancel
+}
+
+func (c *ConfirmButton) cancelLocked() {
+	if c.cancel == nil {
+		return
+	}
+	close(c.cancel)
+	c.cancel = nil
+}
+
+// confirm animates the progress ring on a button until either the hold
+// duration has passed, in which case the action gets called, or cancel gets
+// closed.
+func (d *Device) confirm(index uint8, hold time.Duration, cancel chan struct{}, action func()) {
+	start := time.Now()
+	ticker := time.NewTicker(d.frameDelay())
+	defer ticker.Stop()
+
+	restore := func() {
+		if img := d.Image(index); img != nil {
+			_ = d.writeImage(index, d.keyImage(index, img))
+		}
+	}
+
+	for {
+		select {
+		case <-cancel:
+			restore()
+			return
+
+		case <-ticker.C:
+			progress := float64(time.Since(start)) / float64(hold)
+			if progress >= 1 {
+				restore()
+				action()
+				return
+			}
+
+			img := d.Image(index)
+			if img == nil {
+				continue
+			}
+			_ = d.writeImage(index, d.keyImage(index, progressRing(img, progress, d.Theme().Accent)))
+		}
+	}
+}
+
+// progressRing returns the given image with a ring along its border, filled
+// clockwise from the top up to the given progress, between 0 and 1.
+func progressRing(img image.Image, progress float64, c color.Color) image.Image {
+	b := img.Bounds()
+	out := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Copy(out, image.Point{}, img, b, draw.Src, nil)
+
+	size := float64(b.Dx())
+	center := size / 2
+	outer := center - 1
+	inner := outer - size/12
+	limit := progress * 2 * math.Pi
+
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			dx := float64(x) + 0.5 - center
+			dy := float64(y) + 0.5 - center
+			r := math.Hypot(dx, dy)
+			if r < inner || r > outer {
+				continue
+			}
+
+			// angle clockwise from the top
+			angle := math.Atan2(dx, -dy)
+			if angle < 0 {
+				angle += 2 * math.Pi
+			}
+			if angle <= limit {
+				out.Set(x, y, c)
+			}
+		}
+	}
+
+	return out
+}
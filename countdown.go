@@ -0,0 +1,172 @@
+package streamdeck
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"time"
+
+	"golang.org/x/image/draw"
+)
+
+const (
+	// the countdown flashes this many times when it ends.
+	countdownFlashes    = 3
+	countdownFlashDelay = time.Second / 4
+)
+
+// Countdown phase colors, depending on the remaining time.
+var (
+	countdownGreen  = color.RGBA{0x00, 0xc0, 0x00, 0xff}
+	countdownYellow = color.RGBA{0xff, 0xc0, 0x00, 0xff}
+	countdownRed    = color.RGBA{0xff, 0x00, 0x00, 0xff}
+)
+
+// Countdown is a countdown timer, e.g. for the pomodoro technique, showing
+// the remaining time in large digits spanning a row of buttons. The digits
+// turn from green to yellow to red as time runs out, and flash when the
+// countdown ends. It stops updating while the device is asleep, but keeps
+// counting down.
+type Countdown struct {
+	timer
+	onDone func()
+}
+
+// NewCountdown creates a countdown of the given duration, showing the
+// remaining time across the given buttons. The startPause button starts and
+// pauses the countdown, the reset button stops it and restores the full
+// duration. onDone gets called when the countdown ends. Key events of the
+// countdown's buttons are consumed and not emitted, until it gets closed.
+func (d *Device) NewCountdown(keys []uint8, startPause, reset uint8, duration time.Duration, onDone func()) (*Countdown, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("countdown needs at least one key")
+	}
+
+	c := &Countdown{onDone: onDone}
+	c.timer = timer{
+		d:           d,
+		keys:        keys,
+		startPause:  startPause,
+		reset:       reset,
+		renderState: c.renderLocked,
+		onLimit:     c.end,
+		limited:     true,
+		limit:       duration,
+	}
+	if err := c.render(); err != nil {
+		return nil, err
+	}
+
+	c.register()
+	return c, nil
+}
+
+// Remaining returns the remaining time.
+func (c *Countdown) Remaining() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.remainingLocked()
+}
+
+// SetDuration stops the countdown and sets its duration, e.g. to switch
+// between work and break phases.
+func (c *Countdown) SetDuration(duration time.Duration) error {
+	c.mu.Lock()
+	c.limit = duration
+	c.mu.Unlock()
+
+	return c.Reset()
+}
+
+func (c *Countdown) remainingLocked() time.Duration {
+	if remaining := c.limit - c.elapsedLocked(); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// end flashes the countdown's buttons, unless the device is asleep, and calls
+// onDone.
+func (c *Countdown) end(asleep bool) {
+	if !asleep {
+		c.flash()
+	}
+	if c.onDone != nil {
+		c.onDone()
+	}
+}
+
+// flash flashes the countdown's buttons to signal its end.
+func (c *Countdown) flash() {
+	size := int(c.d.Pixels)
+	on := image.NewUniform(c.d.Theme().Accent)
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(img, img.Bounds(), on, image.Point{}, draw.Src)
+
+	for i := 0; i < countdownFlashes; i++ {
+		for _, key := range c.keys {
+			_ = c.d.SetImage(key, img)
+		}
+		time.Sleep(countdownFlashDelay)
+
+		_ = c.render()
+		time.Sleep(countdownFlashDelay)
+	}
+}
+
+// renderLocked shows the remaining time across the countdown's buttons and
+// labels its control buttons.
+func (c *Countdown) renderLocked() error {
+	remaining := c.remainingLocked()
+	theme := c.d.Theme()
+
+	digits := theme
+	switch {
+	case remaining > c.limit/2:
+		digits.Foreground = countdownGreen
+	case remaining > c.limit/5:
+		digits.Foreground = countdownYellow
+	default:
+		digits.Foreground = countdownRed
+	}
+
+	if err := c.d.setTextAcross(c.keys, theme.formatDuration(time.Duration(seconds(remaining))*time.Second), digits); err != nil {
+		return err
+	}
+	return c.d.setControls(c.startPause, c.reset, c.running, theme)
+}
+
+// setTextAcross renders the text in large letters spanning the given row of
+// buttons.
+func (d *Device) setTextAcross(keys []uint8, text string, theme Theme) error {
+	size := int(d.Pixels)
+	img, err := renderText(size*len(keys), size, text, theme)
+	if err != nil {
+		return err
+	}
+
+	for i, key := range keys {
+		tile := image.NewRGBA(image.Rect(0, 0, size, size))
+		draw.Copy(tile, image.Point{}, img, image.Rect(i*size, 0, (i+1)*size, size), draw.Src, nil)
+		if err := d.SetImage(key, tile); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatSeconds formats the given number of seconds as mm:ss, or h:mm:ss
+// from an hour on.
+func formatSeconds(s int) string {
+	if s >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", s/3600, s/60%60, s%60)
+	}
+	return fmt.Sprintf("%02d:%02d", s/60, s%60)
+}
+
+// seconds returns the given duration in whole seconds, rounded up, so the
+// display only shows 00:00 once the countdown ended.
+func seconds(d time.Duration) int {
+	return int((d + time.Second - 1) / time.Second)
+}
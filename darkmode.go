@@ -0,0 +1,58 @@
+package streamdeck
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// SystemDarkMode returns true if the desktop of the host OS is set to dark
+// mode. It is supported on macOS, Windows and Linux desktops following the
+// GNOME settings.
+func SystemDarkMode() (bool, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		// the key is only set in dark mode, so an error means light mode
+		out, err := exec.Command("defaults", "read", "-g", "AppleInterfaceStyle").Output()
+		if err != nil {
+			return false, nil
+		}
+		return strings.TrimSpace(string(out)) == "Dark", nil
+
+	case "windows":
+		out, err := exec.Command("reg", "query",
+			`HKCU\Software\Microsoft\Windows\CurrentVersion\Themes\Personalize`,
+			"/v", "AppsUseLightTheme").Output()
+		if err != nil {
+			return false, fmt.Errorf("cannot read dark mode setting: %v", err)
+		}
+		return strings.Contains(string(out), "0x0"), nil
+
+	case "linux", "freebsd", "openbsd", "netbsd":
+		out, err := exec.Command("gsettings", "get", "org.gnome.desktop.interface", "color-scheme").Output()
+		if err == nil && strings.Contains(string(out), "prefer-dark") {
+			return true, nil
+		}
+
+		// older desktops only signal dark mode with a dark GTK theme
+		out, err = exec.Command("gsettings", "get", "org.gnome.desktop.interface", "gtk-theme").Output()
+		if err != nil {
+			return false, fmt.Errorf("cannot read dark mode setting: %v", err)
+		}
+		return strings.Contains(strings.ToLower(string(out)), "dark"), nil
+
+	default:
+		return false, fmt.Errorf("dark mode detection is not supported on %s", runtime.GOOS)
+	}
+}
+
+// SystemTheme returns DarkTheme or LightTheme, matching the desktop
+// appearance of the host OS. It falls back to DarkTheme if the setting can't
+// be detected.
+func SystemTheme() Theme {
+	if dark, err := SystemDarkMode(); err == nil && !dark {
+		return LightTheme
+	}
+	return DarkTheme
+}
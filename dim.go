@@ -0,0 +1,71 @@
+package streamdeck
+
+import (
+	"image"
+	"image/color"
+)
+
+// SetKeyDim dims the image of a single button in software, independent of the
+// device's brightness. The level ranges from 0 (black) to 1 (full brightness).
+// The button's current image gets re-sent with the new level.
+func (d Device) SetKeyDim(index uint8, level float64) error {
+	if level < 0 {
+		level = 0
+	}
+	if level > 1 {
+		level = 1
+	}
+
+	d.imagesMutex.Lock()
+	if level == 1 {
+		delete(d.dims, index)
+	} else {
+		d.dims[index] = level
+	}
+	d.imagesMutex.Unlock()
+
+	img := d.Image(index)
+	if img == nil || d.ScreensaverActive() {
+		return nil
+	}
+	return d.writeImage(index, d.keyImage(index, img))
+}
+
+// KeyDim returns the software dim level of a button.
+func (d Device) KeyDim(index uint8) float64 {
+	d.imagesMutex.RLock()
+	defer d.imagesMutex.RUnlock()
+
+	if level, ok := d.dims[index]; ok {
+		return level
+	}
+	return 1
+}
+
+// dimImage returns the given image dimmed to the button's dim level.
+func (d Device) dimImage(index uint8, img image.Image) image.Image {
+	if d.dims == nil {
+		return img
+	}
+
+	level := d.KeyDim(index)
+	if level == 1 {
+		return img
+	}
+
+	rgba := toRGBA(img)
+	b := rgba.Bounds()
+	dimmed := image.NewRGBA(b)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := rgba.RGBAAt(x, y)
+			dimmed.SetRGBA(x, y, color.RGBA{
+				uint8(float64(c.R) * level),
+				uint8(float64(c.G) * level),
+				uint8(float64(c.B) * level),
+				c.A,
+			})
+		}
+	}
+	return dimmed
+}
@@ -0,0 +1,133 @@
+package streamdeck
+
+import (
+	"image"
+	"image/color"
+)
+
+// Dithering defines the dithering algorithm applied to images before they
+// get sent to the device.
+type Dithering int
+
+// Dithering algorithms.
+const (
+	// DitherNone disables dithering.
+	DitherNone Dithering = iota
+	// DitherFloydSteinberg applies Floyd-Steinberg error diffusion.
+	DitherFloydSteinberg
+	// DitherOrdered applies ordered dithering with a 4x4 Bayer matrix.
+	DitherOrdered
+)
+
+// panelLevels is the number of color levels per channel the panels can
+// display without banding.
+const panelLevels = 64
+
+// bayer4x4 is the threshold matrix used for ordered dithering.
+var bayer4x4 = [4][4]float64{
+	{0, 8, 2, 10},
+	{12, 4, 14, 6},
+	{3, 11, 1, 9},
+	{15, 7, 13, 5},
+}
+
+// SetDithering sets the dithering algorithm applied to images before they get
+// sent to the device. Dithering reduces banding on gradient-heavy images,
+// which is most noticeable on devices using the BMP image format.
+func (d *Device) SetDithering(dithering Dithering) {
+	d.dithering = dithering
+}
+
+// dither returns the given image dithered with the given algorithm.
+func dither(img image.Image, dithering Dithering) image.Image {
+	switch dithering {
+	case DitherFloydSteinberg:
+		return ditherFloydSteinberg(img)
+	case DitherOrdered:
+		return ditherOrdered(img)
+	default:
+		return img
+	}
+}
+
+// ditherFloydSteinberg returns the given image quantized to the panel's color
+// levels, diffusing the quantization error onto neighboring pixels.
+func ditherFloydSteinberg(img image.Image) image.Image {
+	rgba := toRGBA(img)
+	b := rgba.Bounds()
+	w, h := b.Dx(), b.Dy()
+
+	// working buffer holding the red, green and blue channel of each pixel
+	buf := make([][3]float64, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := rgba.RGBAAt(b.Min.X+x, b.Min.Y+y)
+			buf[y*w+x] = [3]float64{float64(c.R), float64(c.G), float64(c.B)}
+		}
+	}
+
+	out := image.NewRGBA(b)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var q [3]uint8
+			for ch := 0; ch < 3; ch++ {
+				old := buf[y*w+x][ch]
+				q[ch] = quantize(old)
+				e := old - float64(q[ch])
+
+				if x+1 < w {
+					buf[y*w+x+1][ch] += e * 7 / 16
+				}
+				if y+1 < h {
+					if x > 0 {
+						buf[(y+1)*w+x-1][ch] += e * 3 / 16
+					}
+					buf[(y+1)*w+x][ch] += e * 5 / 16
+					if x+1 < w {
+						buf[(y+1)*w+x+1][ch] += e * 1 / 16
+					}
+				}
+			}
+
+			a := rgba.RGBAAt(b.Min.X+x, b.Min.Y+y).A
+			out.SetRGBA(b.Min.X+x, b.Min.Y+y, color.RGBA{q[0], q[1], q[2], a})
+		}
+	}
+	return out
+}
+
+// ditherOrdered returns the given image quantized to the panel's color
+// levels, using a Bayer matrix to offset the quantization threshold.
+func ditherOrdered(img image.Image) image.Image {
+	rgba := toRGBA(img)
+	b := rgba.Bounds()
+	step := 255.0 / (panelLevels - 1)
+
+	out := image.NewRGBA(b)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			offset := (bayer4x4[y%4][x%4]/16 - 0.5) * step
+			c := rgba.RGBAAt(x, y)
+			out.SetRGBA(x, y, color.RGBA{
+				quantize(float64(c.R) + offset),
+				quantize(float64(c.G) + offset),
+				quantize(float64(c.B) + offset),
+				c.A,
+			})
+		}
+	}
+	return out
+}
+
+// quantize returns the panel color level closest to the given channel value.
+func quantize(v float64) uint8 {
+	if v <= 0 {
+		return 0
+	}
+	if v >= 255 {
+		return 255
+	}
+
+	step := 255.0 / (panelLevels - 1)
+	return uint8(float64(int(v/step+0.5))*step + 0.5)
+}
@@ -0,0 +1,63 @@
+package streamdeck
+
+import (
+	"image"
+	"image/color"
+)
+
+// SetKeyEnabled enables or disables a single button. Disabled buttons don't
+// emit key events and their image is shown desaturated and dimmed. Enabling
+// the button again restores its image.
+func (d Device) SetKeyEnabled(index uint8, enabled bool) error {
+	d.imagesMutex.Lock()
+	if enabled {
+		delete(d.disabled, index)
+	} else {
+		d.disabled[index] = true
+	}
+	d.imagesMutex.Unlock()
+
+	img := d.Image(index)
+	if img == nil || d.ScreensaverActive() {
+		return nil
+	}
+	return d.writeImage(index, d.keyImage(index, img))
+}
+
+// KeyEnabled returns true if the button is enabled.
+func (d Device) KeyEnabled(index uint8) bool {
+	if d.disabled == nil {
+		return true
+	}
+
+	d.imagesMutex.RLock()
+	defer d.imagesMutex.RUnlock()
+	return !d.disabled[index]
+}
+
+// keyImage returns the given image the way it should be rendered on the
+// button, applying its dim level and disabled state.
+func (d Device) keyImage(index uint8, img image.Image) image.Image {
+	img = d.dimImage(index, img)
+	if !d.KeyEnabled(index) {
+		img = greyOut(img)
+	}
+	return img
+}
+
+// greyOut returns the given image desaturated and dimmed to half its
+// brightness.
+func greyOut(img image.Image) image.Image {
+	rgba := toRGBA(img)
+	b := rgba.Bounds()
+	out := image.NewRGBA(b)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := rgba.RGBAAt(x, y)
+			// luma as defined by ITU-R BT.601
+			l := (0.299*float64(c.R) + 0.587*float64(c.G) + 0.114*float64(c.B)) / 2
+			out.SetRGBA(x, y, color.RGBA{uint8(l), uint8(l), uint8(l), c.A})
+		}
+	}
+	return out
+}
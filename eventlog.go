@@ -0,0 +1,72 @@
+package streamdeck
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// Event types written to the event log.
+const (
+	EventPress   = "press"
+	EventRelease = "release"
+	EventSleep   = "sleep"
+	EventWake    = "wake"
+	EventClose   = "close"
+)
+
+// EventLogEntry is a line of the event log.
+type EventLogEntry struct {
+	Time   time.Time `json:"time"`
+	Serial string    `json:"serial"`
+	Event  string    `json:"event"`
+	// Key is the index of the key for press and release events.
+	Key *uint8 `json:"key,omitempty"`
+}
+
+type eventLog struct {
+	sync.Mutex
+	enc *json.Encoder
+}
+
+// SetEventLog enables logging all key events, sleep state changes and closing
+// the device to w, as one JSON-encoded EventLogEntry per line, e.g. for
+// auditing kiosk usage. Key events are logged before middleware runs. A nil
+// writer disables the event log.
+func (d *Device) SetEventLog(w io.Writer) {
+	var l *eventLog
+	if w != nil {
+		l = &eventLog{enc: json.NewEncoder(w)}
+	}
+
+	d.hooksMutex.Lock()
+	defer d.hooksMutex.Unlock()
+
+	d.eventLog = l
+}
+
+// logEvent writes an event to the event log, if enabled. key is ignored for
+// events other than press and release.
+func (d *Device) logEvent(event string, key uint8) {
+	d.hooksMutex.RLock()
+	l := d.eventLog
+	d.hooksMutex.RUnlock()
+
+	if l == nil {
+		return
+	}
+
+	e := EventLogEntry{
+		Time:   time.Now(),
+		Serial: d.Serial,
+		Event:  event,
+	}
+	if event == EventPress || event == EventRelease {
+		e.Key = &key
+	}
+
+	l.Lock()
+	defer l.Unlock()
+	_ = l.enc.Encode(e)
+}
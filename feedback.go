@@ -0,0 +1,33 @@
+package streamdeck
+
+// Feedback gets called for every key event, e.g. to play a click sound.
+type Feedback func(Key)
+
+// SetFeedback sets a function that gets called for every key event, including
+// events consumed by middleware and components, so apps can confirm key
+// presses with a sound or other feedback. It runs in its own goroutine, which
+// receives the key events in order, and doesn't delay the event. A nil
+// function disables the feedback.
+func (d *Device) SetFeedback(fn Feedback) {
+	d.hooksMutex.Lock()
+	defer d.hooksMutex.Unlock()
+
+	d.feedback = fn
+}
+
+func (d *Device) currentFeedback() Feedback {
+	d.hooksMutex.RLock()
+	defer d.hooksMutex.RUnlock()
+
+	return d.feedback
+}
+
+// runFeedback calls the feedback function for every key event received from
+// the given channel, in order, until the channel gets closed.
+func (d *Device) runFeedback(keys chan Key) {
+	for key := range keys {
+		if feedback := d.currentFeedback(); feedback != nil {
+			feedback(key)
+		}
+	}
+}
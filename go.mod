@@ -6,5 +6,6 @@ require (
 	github.com/karalabe/hid v1.0.1-0.20190806082151-9c14560f9ee8
 	github.com/muesli/coral v1.0.0
 	github.com/nfnt/resize v0.0.0-20180221191011-83c6a9932646
+	github.com/skip2/go-qrcode v0.0.0-20200617195104-da1b6568686e
 	golang.org/x/image v0.7.0
 )
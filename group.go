@@ -0,0 +1,173 @@
+package streamdeck
+
+import (
+	"sync"
+	"time"
+)
+
+// DeviceGroup applies brightness, sleep and wake across multiple devices in
+// lockstep, so several decks behave as one: a key event on any of them
+// counts as activity for all of them, and when one falls asleep or wakes
+// up, all others follow.
+type DeviceGroup struct {
+	devices  []*Device
+	callback func(asleep bool)
+	remove   []func()
+
+	mu     sync.Mutex
+	asleep bool
+}
+
+// NewDeviceGroup creates a group of opened devices. The group takes over the
+// sleep state callbacks of its devices, use the group's OnSleepStateChange
+// instead.
+func NewDeviceGroup(devices ...*Device) *DeviceGroup {
+	g := &DeviceGroup{
+		devices: devices,
+	}
+
+	for _, d := range devices {
+		d := d
+		d.OnSleepStateChange(func(asleep bool) {
+			g.follow(d, asleep)
+		})
+		g.remove = append(g.remove, d.Use(func(k Key) (Key, bool) {
+			g.touch(d)
+			return k, true
+		}))
+	}
+
+	return g
+}
+
+// Close dissolves the group, so its devices sleep and wake independently
+// again. It removes the sleep state callbacks of the devices.
+func (g *DeviceGroup) Close() {
+	for _, remove := range g.remove {
+		remove()
+	}
+	for _, d := range g.devices {
+		d.OnSleepStateChange(nil)
+	}
+}
+
+// Devices returns the devices of the group.
+func (g *DeviceGroup) Devices() []*Device {
+	return g.devices
+}
+
+// OnSleepStateChange sets a callback, which gets called whenever the group
+// falls asleep or wakes up.
+func (g *DeviceGroup) OnSleepStateChange(fn func(asleep bool)) {
+	g.callback = fn
+}
+
+// SetBrightness sets the brightness of all devices.
+func (g *DeviceGroup) SetBrightness(percent uint8) error {
+	return g.each(nil, func(d *Device) error {
+		return d.SetBrightness(percent)
+	})
+}
+
+// SetSleepTimeout sets the time after which all devices will sleep if none of
+// them received key events.
+func (g *DeviceGroup) SetSleepTimeout(t time.Duration) {
+	for _, d := range g.devices {
+		d.SetSleepTimeout(t)
+	}
+}
+
+// Sleep puts all devices asleep.
+func (g *DeviceGroup) Sleep() error {
+	if err := g.setAsleep(nil, true); err != nil {
+		return err
+	}
+
+	g.notifySleepState(true)
+	return nil
+}
+
+// Wake wakes all devices from sleep.
+func (g *DeviceGroup) Wake() error {
+	if err := g.setAsleep(nil, false); err != nil {
+		return err
+	}
+
+	g.notifySleepState(false)
+	return nil
+}
+
+// follow puts the other devices of the group into the same sleep state as
+// the given device.
+func (g *DeviceGroup) follow(from *Device, asleep bool) {
+	_ = g.setAsleep(from, asleep)
+	g.notifySleepState(asleep)
+}
+
+// setAsleep puts all devices except skip asleep or wakes them up. Devices
+// already in that state are left alone. It goes through Sleep and Wake, so
+// every device notifies its own sleep state handlers, e.g. to stop a clock on
+// a follower falling asleep. Their callbacks call follow again, which is a
+// no-op for devices already in that state.
+func (g *DeviceGroup) setAsleep(skip *Device, asleep bool) error {
+	return g.each(skip, func(d *Device) error {
+		if asleep {
+			return d.Sleep()
+		}
+		return d.Wake()
+	})
+}
+
+// notifySleepState calls the group's callback if its sleep state changed, so
+// it only fires once when several devices fall asleep or wake up.
+func (g *DeviceGroup) notifySleepState(asleep bool) {
+	g.mu.Lock()
+	changed := g.asleep != asleep
+	g.asleep = asleep
+	g.mu.Unlock()
+
+	if changed && g.callback != nil {
+		g.callback(asleep)
+	}
+}
+
+// touch restarts the sleep timeout of all other devices of the group.
+func (g *DeviceGroup) touch(from *Device) {
+	for _, d := range g.devices {
+		if d == from {
+			continue
+		}
+
+		d.sleepMutex.Lock()
+		d.lastActionTime = time.Now()
+		d.resetSleepTimer()
+		d.sleepMutex.Unlock()
+	}
+}
+
+// each concurrently calls fn for all devices except skip, and returns the
+// first error.
+func (g *DeviceGroup) each(skip *Device, fn func(d *Device) error) error {
+	var wg sync.WaitGroup
+	errs := make([]error, len(g.devices))
+
+	for i, d := range g.devices {
+		if d == skip {
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, d *Device) {
+			defer wg.Done()
+			errs[i] = fn(d)
+		}(i, d)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
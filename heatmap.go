@@ -0,0 +1,70 @@
+package streamdeck
+
+import (
+	"image"
+	"image/color"
+
+	"golang.org/x/image/draw"
+)
+
+// Heatmap returns an image for every button, colored by how often it got
+// pressed compared to the most pressed button: from dark blue for buttons that
+// were never pressed to red for the most pressed one. Requires statistics to be
+// enabled with EnableStats.
+func (d *Device) Heatmap() map[uint8]image.Image {
+	stats := d.Stats()
+
+	var most uint64
+	for _, s := range stats {
+		if s.Presses > most {
+			most = s.Presses
+		}
+	}
+
+	imgs := make(map[uint8]image.Image, d.Keys)
+	for i := uint8(0); i < d.Keys; i++ {
+		var heat float64
+		if most > 0 {
+			heat = float64(stats[i].Presses) / float64(most)
+		}
+
+		img := image.NewRGBA(image.Rect(0, 0, int(d.Pixels), int(d.Pixels)))
+		draw.Draw(img, img.Bounds(), image.NewUniform(heatColor(heat)), image.Point{}, draw.Src)
+		imgs[i] = img
+	}
+
+	return imgs
+}
+
+// ShowHeatmap shows the usage heatmap on the device. The images previously
+// set on the buttons are kept and can be shown again with Redraw.
+func (d *Device) ShowHeatmap() error {
+	for i, img := range d.Heatmap() {
+		if err := d.writeImage(i, img); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// heatColor returns the color for the given heat, between 0 and 1, going from
+// dark blue over green and yellow to red.
+func heatColor(heat float64) color.RGBA {
+	stops := []color.RGBA{
+		{0, 0, 64, 255},
+		{0, 160, 0, 255},
+		{255, 220, 0, 255},
+		{255, 0, 0, 255},
+	}
+
+	pos := heat * float64(len(stops)-1)
+	i := int(pos)
+	if i >= len(stops)-1 {
+		return stops[len(stops)-1]
+	}
+
+	p := pos - float64(i)
+	a, b := stops[i], stops[i+1]
+	return color.RGBA{lerp(a.R, b.R, p), lerp(a.G, b.G, p), lerp(a.B, b.B, p), 255}
+}
@@ -0,0 +1,71 @@
+package streamdeck
+
+import (
+	"context"
+	"time"
+)
+
+// HotplugEvent is emitted by WatchDevices when a device gets attached or
+// detached.
+type HotplugEvent struct {
+	Device   Device
+	Attached bool
+}
+
+// WatchDevices polls for attached devices every interval and emits an event
+// whenever a device gets attached or detached, until the context is done.
+// Devices already attached when starting to watch are emitted as attached
+// first.
+func WatchDevices(ctx context.Context, interval time.Duration) (<-chan HotplugEvent, error) {
+	devs, err := Devices()
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan HotplugEvent)
+	go func() {
+		defer close(ch)
+
+		known := map[string]Device{}
+		for {
+			current := make(map[string]Device, len(devs))
+			for _, d := range devs {
+				current[d.ID] = d
+			}
+
+			var events []HotplugEvent
+			for id, d := range current {
+				if _, ok := known[id]; !ok {
+					events = append(events, HotplugEvent{Device: d, Attached: true})
+				}
+			}
+			for id, d := range known {
+				if _, ok := current[id]; !ok {
+					events = append(events, HotplugEvent{Device: d})
+				}
+			}
+			known = current
+
+			for _, e := range events {
+				select {
+				case ch <- e:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			select {
+			case <-time.After(interval):
+			case <-ctx.Done():
+				return
+			}
+
+			// keep the last known devices if enumeration fails temporarily
+			if d, err := Devices(); err == nil {
+				devs = d
+			}
+		}
+	}()
+
+	return ch, nil
+}
@@ -0,0 +1,198 @@
+package streamdeck
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Keypad turns buttons into an input mode for entering text, e.g. a PIN on a
+// kiosk: every character button shows its character and appends it to the
+// input, a backspace button removes the last character and a confirm button
+// finishes the input.
+type Keypad struct {
+	d         *Device
+	chars     map[uint8]rune
+	backspace uint8
+	confirm   uint8
+	onConfirm func(string)
+	maxLength int
+	remove    []func()
+
+	mu     sync.Mutex
+	input  []rune
+	active bool
+}
+
+// NewKeypad creates a keypad. The i-th button in keys enters the i-th
+// character of charset, e.g. "0123456789". onConfirm gets called with the
+// input when the confirm button is pressed, after which the input is cleared.
+// While the keypad is active, key events of its buttons are consumed and not
+// emitted. Stop deactivates the keypad temporarily, Close removes it.
+func (d *Device) NewKeypad(keys []uint8, charset string, backspace, confirm uint8, onConfirm func(string)) (*Keypad, error) {
+	runes := []rune(charset)
+	if len(runes) != len(keys) {
+		return nil, fmt.Errorf("keypad has %d keys but %d characters", len(keys), len(runes))
+	}
+	if backspace == confirm {
+		return nil, fmt.Errorf("keypad needs distinct backspace and confirm keys, got %d twice", backspace)
+	}
+	seen := make(map[uint8]bool, len(keys))
+	for _, key := range keys {
+		if key == backspace || key == confirm {
+			return nil, fmt.Errorf("key %d can't enter a character and backspace or confirm", key)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("key %d can't enter two characters", key)
+		}
+		seen[key] = true
+	}
+
+	k := &Keypad{
+		d:         d,
+		chars:     make(map[uint8]rune, len(keys)),
+		backspace: backspace,
+		confirm:   confirm,
+		onConfirm: onConfirm,
+		active:    true,
+	}
+	for i, key := range keys {
+		k.chars[key] = runes[i]
+	}
+
+	if err := k.render(); err != nil {
+		return nil, err
+	}
+
+	k.remove = []func(){
+		d.Use(k.handleKey),
+		d.onThemeChange(func() error {
+			k.mu.Lock()
+			active := k.active
+			k.mu.Unlock()
+
+			if !active {
+				return nil
+			}
+			return k.render()
+		}),
+	}
+	return k, nil
+}
+
+// Close removes the keypad, so key events of its buttons get emitted again.
+// Unlike a stopped keypad, it can't be started again.
+func (k *Keypad) Close() {
+	k.Stop()
+	for _, remove := range k.remove {
+		remove()
+	}
+}
+
+// SetMaxLength limits the number of characters that can be entered. 0 means
+// no limit.
+func (k *Keypad) SetMaxLength(n int) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.maxLength = n
+}
+
+// Input returns the characters entered so far.
+func (k *Keypad) Input() string {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	return string(k.input)
+}
+
+// Clear clears the input.
+func (k *Keypad) Clear() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.input = nil
+}
+
+// Stop deactivates the keypad. Key events of its buttons get emitted again,
+// but the buttons keep their images until they get replaced.
+func (k *Keypad) Stop() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.active = false
+	k.input = nil
+}
+
+// Start activates the keypad again after it was stopped, rendering its
+// buttons.
+func (k *Keypad) Start() error {
+	k.mu.Lock()
+	k.active = true
+	k.mu.Unlock()
+
+	return k.render()
+}
+
+// render sets the labels of all keypad buttons.
+func (k *Keypad) render() error {
+	labels := map[uint8]string{
+		k.backspace: "←",
+		k.confirm:   "OK",
+	}
+	for key, c := range k.chars {
+		labels[key] = string(c)
+	}
+
+	for key, label := range labels {
+		img, err := renderLabel(int(k.d.Pixels), label, k.d.Theme())
+		if err != nil {
+			return err
+		}
+		if err := k.d.SetImage(key, img); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// handleKey is the middleware collecting the input.
+func (k *Keypad) handleKey(key Key) (Key, bool) {
+	k.mu.Lock()
+	if !k.active {
+		k.mu.Unlock()
+		return key, true
+	}
+
+	c, isChar := k.chars[key.Index]
+	if !isChar && key.Index != k.backspace && key.Index != k.confirm {
+		k.mu.Unlock()
+		return key, true
+	}
+	if !key.Pressed {
+		k.mu.Unlock()
+		return key, false
+	}
+
+	var confirmed string
+	var done bool
+	switch {
+	case isChar:
+		if k.maxLength == 0 || len(k.input) < k.maxLength {
+			k.input = append(k.input, c)
+		}
+	case key.Index == k.backspace:
+		if len(k.input) > 0 {
+			k.input = k.input[:len(k.input)-1]
+		}
+	case key.Index == k.confirm:
+		confirmed, done = string(k.input), true
+		k.input = nil
+	}
+	k.mu.Unlock()
+
+	if done && k.onConfirm != nil {
+		k.onConfirm(confirmed)
+	}
+	return key, false
+}
@@ -0,0 +1,27 @@
+package streamdeck
+
+import "testing"
+
+func TestNewKeypadInvalidKeys(t *testing.T) {
+	tests := []struct {
+		name               string
+		keys               []uint8
+		charset            string
+		backspace, confirm uint8
+	}{
+		{"more characters than keys", []uint8{0, 1}, "012", 3, 4},
+		{"backspace is confirm", []uint8{0, 1}, "01", 3, 3},
+		{"character on backspace", []uint8{0, 3}, "01", 3, 4},
+		{"character on confirm", []uint8{0, 4}, "01", 3, 4},
+		{"two characters on one key", []uint8{0, 0}, "01", 3, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Device{Keys: 6}
+			if _, err := d.NewKeypad(tt.keys, tt.charset, tt.backspace, tt.confirm, nil); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
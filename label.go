@@ -0,0 +1,203 @@
+package streamdeck
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"strings"
+	"sync"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// ellipsis marks text that got cut off.
+const ellipsis = "…"
+
+var (
+	labelFont     *opentype.Font
+	labelFontOnce sync.Once
+	labelFontErr  error
+
+	boldLabelFont     *opentype.Font
+	boldLabelFontOnce sync.Once
+	boldLabelFontErr  error
+)
+
+// loadLabelFont parses the font used for labels once.
+func loadLabelFont(bold bool) (*opentype.Font, error) {
+	if bold {
+		boldLabelFontOnce.Do(func() {
+			boldLabelFont, boldLabelFontErr = opentype.Parse(gobold.TTF)
+		})
+		return boldLabelFont, boldLabelFontErr
+	}
+
+	labelFontOnce.Do(func() {
+		labelFont, labelFontErr = opentype.Parse(goregular.TTF)
+	})
+	return labelFont, labelFontErr
+}
+
+// renderLabel returns a square image of the given size showing the text
+// centered on the theme's background.
+func renderLabel(size int, text string, theme Theme) (image.Image, error) {
+	return renderText(size, size, text, theme)
+}
+
+// renderText returns an image of the given size showing the text centered on
+// the theme's background. The text gets wrapped at spaces and line breaks,
+// and the font size is chosen as large as possible while still fitting the
+// text, between the theme's minimum and maximum font size. Text that doesn't
+// fit at the minimum font size gets cut off with an ellipsis.
+func renderText(width, height int, text string, theme Theme) (*image.RGBA, error) {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(theme.Background), image.Point{}, draw.Src)
+	if text == "" {
+		return roundCorners(img, theme.CornerRadius), nil
+	}
+
+	f := theme.Font
+	if f == nil {
+		var err error
+		if f, err = loadLabelFont(theme.Bold); err != nil {
+			return nil, err
+		}
+	}
+
+	maxWidth := fixed.I(width * 9 / 10)
+	maxHeight := fixed.I(height * 9 / 10)
+	minPoints := math.Max(4, theme.MinFontSize)
+	maxPoints := float64(height) * 0.6
+	if theme.MaxFontSize > 0 {
+		maxPoints = theme.MaxFontSize
+	}
+	for points := math.Max(maxPoints, minPoints); ; points = math.Max(points-1, minPoints) {
+		face, err := opentype.NewFace(f, &opentype.FaceOptions{
+			Size:    points,
+			DPI:     72,
+			Hinting: font.HintingFull,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		dr := font.Drawer{
+			Dst:  img,
+			Src:  image.NewUniform(theme.Foreground),
+			Face: face,
+		}
+		m := face.Metrics()
+		lines := wrapText(dr, text, maxWidth)
+		if points > minPoints && !textFits(dr, lines, maxWidth, maxHeight) {
+			_ = face.Close()
+			continue
+		}
+
+		// at the minimum font size, cut off whatever still doesn't fit
+		n := 1
+		if maxHeight > m.Ascent+m.Descent {
+			n += int((maxHeight - m.Ascent - m.Descent) / m.Height)
+		}
+		if len(lines) > n {
+			lines = append(lines[:n-1], strings.Join(lines[n-1:], " "))
+		}
+		for i := range lines {
+			lines[i] = ellipsize(dr, lines[i], maxWidth)
+		}
+
+		textHeight := fixed.Int26_6(len(lines)-1)*m.Height + m.Ascent + m.Descent
+		y := (fixed.I(height)-textHeight)/2 + m.Ascent
+		for _, line := range lines {
+			dr.Dot = fixed.Point26_6{
+				X: (fixed.I(width) - dr.MeasureString(line)) / 2,
+				Y: y,
+			}
+			dr.DrawString(line)
+			y += m.Height
+		}
+		_ = face.Close()
+		break
+	}
+
+	return roundCorners(img, theme.CornerRadius), nil
+}
+
+// wrapText splits the text into lines at line breaks, and at spaces wherever
+// a line would get wider than maxWidth. Words wider than maxWidth get a line
+// of their own.
+func wrapText(dr font.Drawer, text string, maxWidth fixed.Int26_6) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			if line == "" {
+				line = word
+				continue
+			}
+			if dr.MeasureString(line+" "+word) > maxWidth {
+				lines = append(lines, line)
+				line = word
+				continue
+			}
+			line += " " + word
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// textFits returns true if all lines fit into the given width and height.
+func textFits(dr font.Drawer, lines []string, maxWidth, maxHeight fixed.Int26_6) bool {
+	m := dr.Face.Metrics()
+	if fixed.Int26_6(len(lines)-1)*m.Height+m.Ascent+m.Descent > maxHeight {
+		return false
+	}
+	for _, line := range lines {
+		if dr.MeasureString(line) > maxWidth {
+			return false
+		}
+	}
+	return true
+}
+
+// ellipsize shortens the line until it fits into maxWidth together with an
+// ellipsis. Lines that already fit are returned unchanged.
+func ellipsize(dr font.Drawer, line string, maxWidth fixed.Int26_6) string {
+	if dr.MeasureString(line) <= maxWidth {
+		return line
+	}
+
+	r := []rune(line)
+	for len(r) > 0 && dr.MeasureString(string(r)+ellipsis) > maxWidth {
+		r = r[:len(r)-1]
+	}
+	return strings.TrimRight(string(r), " ") + ellipsis
+}
+
+// roundCorners returns the given image with its corners outside the given
+// radius set to black, which is how unlit pixels look on the device.
+func roundCorners(img *image.RGBA, radius int) *image.RGBA {
+	if radius <= 0 {
+		return img
+	}
+
+	b := img.Bounds()
+	r := float64(radius)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			// distance from the center of the corner's circle, if in a corner
+			cx := math.Max(math.Max(r-float64(x-b.Min.X)-0.5, float64(x-b.Max.X+1)+r-0.5), 0)
+			cy := math.Max(math.Max(r-float64(y-b.Min.Y)-0.5, float64(y-b.Max.Y+1)+r-0.5), 0)
+			if math.Hypot(cx, cy) > r {
+				img.Set(x, y, color.Black)
+			}
+		}
+	}
+
+	return img
+}
@@ -0,0 +1,72 @@
+package streamdeck
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// testDrawer returns a drawer using the label font at the given size.
+func testDrawer(t *testing.T, points float64) font.Drawer {
+	t.Helper()
+
+	f, err := loadLabelFont(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: points, DPI: 72})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return font.Drawer{Face: face}
+}
+
+func TestWrapText(t *testing.T) {
+	dr := testDrawer(t, 12)
+	maxWidth := dr.MeasureString("Volume Up")
+
+	tests := []struct {
+		name  string
+		text  string
+		lines []string
+	}{
+		{"fits", "Volume Up", []string{"Volume Up"}},
+		{"wraps at spaces", "Volume Up Now", []string{"Volume Up", "Now"}},
+		{"collapses spaces", "Volume   Up", []string{"Volume Up"}},
+		{"keeps line breaks", "Mute\nMic", []string{"Mute", "Mic"}},
+		{"long word on its own line", "A Microphone", []string{"A", "Microphone"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := wrapText(dr, tt.text, maxWidth)
+			if !reflect.DeepEqual(lines, tt.lines) {
+				t.Errorf("expected %q, got %q", tt.lines, lines)
+			}
+		})
+	}
+}
+
+func TestEllipsize(t *testing.T) {
+	dr := testDrawer(t, 12)
+	maxWidth := dr.MeasureString("Micro")
+
+	if s := ellipsize(dr, "Mic", maxWidth); s != "Mic" {
+		t.Errorf("expected a fitting line to stay unchanged, got %q", s)
+	}
+
+	s := ellipsize(dr, "Microphone", maxWidth)
+	if !strings.HasSuffix(s, ellipsis) {
+		t.Errorf("expected %q to end with an ellipsis", s)
+	}
+	if w := dr.MeasureString(s); w > maxWidth {
+		t.Errorf("expected %q to fit into %v, got %v", s, maxWidth, w)
+	}
+	if w := dr.MeasureString(ellipsize(dr, "Microphone", fixed.I(0))); w != dr.MeasureString(ellipsis) {
+		t.Errorf("expected only an ellipsis if nothing fits, got a width of %v", w)
+	}
+}
@@ -0,0 +1,156 @@
+package streamdeck
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// lockPath returns the path of the advisory lock file of the device. It
+// contains the PID of the process managing the device.
+func (d Device) lockPath() string {
+	name := d.Serial
+	if name == "" {
+		name = d.ID
+	}
+
+	name = strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, name)
+	return filepath.Join(os.TempDir(), "streamdeck-"+name+".lock")
+}
+
+// LockOwner returns the PID of another process holding the advisory lock of
+// the device, e.g. a daemon managing its layout, and true if there is one.
+// Stale locks of processes that no longer run are ignored.
+func (d Device) LockOwner() (int, bool) {
+	b, err := ioutil.ReadFile(d.lockPath())
+	if err != nil {
+		return 0, false
+	}
+	return lockOwner(b)
+}
+
+// lockOwner returns the PID stored in the given lock file contents and true,
+// if it belongs to another running process.
+func lockOwner(b []byte) (int, bool) {
+	pid, err := strconv.Atoi(strings.TrimSpace(string(b)))
+	if err != nil || pid == os.Getpid() || !processAlive(pid) {
+		return 0, false
+	}
+	return pid, true
+}
+
+// ErrDeviceLocked is returned by Lock if another process holds the lock of
+// the device.
+var ErrDeviceLocked = errors.New("device is locked by another process")
+
+// Lock acquires the advisory lock of the device, so other processes opting
+// into locking, e.g. a second daemon, don't fight over the device. It returns
+// ErrDeviceLocked if another running process holds the lock, unless override
+// is set, which takes the lock over. LockOwner returns the holding process.
+// Close releases the lock.
+func (d *Device) Lock(override bool) error {
+	path := d.lockPath()
+
+	b, err := ioutil.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		// the lock is free
+	case err != nil:
+		return err
+	default:
+		if _, ok := lockOwner(b); ok && !override {
+			return ErrDeviceLocked
+		}
+
+		// the lock is stale, ours or being taken over
+		if err := removeLock(path, b); err != nil {
+			return err
+		}
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if os.IsExist(err) {
+		// another process acquired the lock in the meantime
+		return ErrDeviceLocked
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = f.WriteString(strconv.Itoa(os.Getpid()))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return err
+	}
+
+	d.locked = true
+	return nil
+}
+
+// removeLock removes the lock file at path, as long as it still has the
+// given contents. Another process might have replaced a stale lock with its
+// own since it got read, which must not be removed.
+func removeLock(path string, contents []byte) error {
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(b, contents) {
+		return ErrDeviceLocked
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Unlock releases the advisory lock of the device, if held.
+func (d *Device) Unlock() error {
+	if !d.locked {
+		return nil
+	}
+	d.locked = false
+
+	b, err := ioutil.ReadFile(d.lockPath())
+	if err != nil {
+		return nil
+	}
+	if strings.TrimSpace(string(b)) != strconv.Itoa(os.Getpid()) {
+		// the lock has been taken over
+		return nil
+	}
+	return os.Remove(d.lockPath())
+}
+
+// processAlive returns true if a process with the given PID is running.
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		// finding a process only succeeds on Windows if it is running
+		return true
+	}
+
+	err = p.Signal(syscall.Signal(0))
+	return err == nil || os.IsPermission(err)
+}
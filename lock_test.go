@@ -0,0 +1,87 @@
+package streamdeck
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func testLockDevice(t *testing.T) *Device {
+	t.Helper()
+
+	d := &Device{Serial: "test" + strconv.Itoa(os.Getpid())}
+	_ = os.Remove(d.lockPath())
+	return d
+}
+
+func TestLock(t *testing.T) {
+	d := testLockDevice(t)
+	if err := d.Lock(false); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := d.LockOwner(); ok {
+		t.Error("own lock reported as held by another process")
+	}
+
+	if err := d.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(d.lockPath()); !os.IsNotExist(err) {
+		t.Errorf("lock file not removed: %v", err)
+	}
+}
+
+func TestLockHeldByOtherProcess(t *testing.T) {
+	d := testLockDevice(t)
+	defer os.Remove(d.lockPath())
+
+	// the parent process, e.g. go test, is running
+	other := strconv.Itoa(os.Getppid())
+	if err := ioutil.WriteFile(d.lockPath(), []byte(other), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if pid, ok := d.LockOwner(); !ok || pid != os.Getppid() {
+		t.Errorf("expected lock owner %d, got %d", os.Getppid(), pid)
+	}
+	if err := d.Lock(false); err != ErrDeviceLocked {
+		t.Errorf("expected ErrDeviceLocked, got %v", err)
+	}
+
+	if err := d.Lock(true); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRemoveLockReplaced(t *testing.T) {
+	dir, err := ioutil.TempDir("", "streamdeck")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "test.lock")
+	if err := ioutil.WriteFile(path, []byte("2"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// another process replaced the stale lock we read
+	if err := removeLock(path, []byte("1")); err != ErrDeviceLocked {
+		t.Errorf("expected ErrDeviceLocked, got %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("replaced lock file got removed: %v", err)
+	}
+
+	if err := removeLock(path, []byte("2")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("lock file not removed: %v", err)
+	}
+}
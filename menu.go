@@ -0,0 +1,219 @@
+package streamdeck
+
+import (
+	"fmt"
+	"image"
+	"sync"
+)
+
+// MenuItem is an entry of a Menu. Items with an icon show the icon, all
+// others their label.
+type MenuItem struct {
+	Label string
+	Icon  image.Image
+}
+
+// Menu displays a list of items across a set of buttons, with buttons to page
+// through the list when it doesn't fit.
+type Menu struct {
+	d          *Device
+	keys       []uint8
+	prev, next uint8
+	onSelect   func(int, MenuItem)
+	remove     []func()
+
+	mu    sync.Mutex
+	items []MenuItem
+	page  int
+}
+
+// NewMenu creates a menu showing the items on the given buttons, using prev
+// and next to switch pages. onSelect gets called with the index and the item
+// when an item's button is pressed. Key events of the menu's buttons are
+// consumed and not emitted. Close the menu before creating another one on the
+// same buttons, e.g. when switching to another page of the application.
+func (d *Device) NewMenu(keys []uint8, prev, next uint8, items []MenuItem, onSelect func(int, MenuItem)) (*Menu, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("menu needs at least one key")
+	}
+	if prev == next {
+		return nil, fmt.Errorf("menu needs distinct keys to switch pages, got %d twice", prev)
+	}
+	for _, k := range keys {
+		if k == prev || k == next {
+			return nil, fmt.Errorf("key %d can't show an item and switch pages", k)
+		}
+	}
+
+	m := &Menu{
+		d:        d,
+		keys:     keys,
+		prev:     prev,
+		next:     next,
+		onSelect: onSelect,
+		items:    items,
+	}
+	if err := m.render(); err != nil {
+		return nil, err
+	}
+
+	m.remove = []func(){
+		d.Use(m.handleKey),
+		d.onThemeChange(m.render),
+	}
+	return m, nil
+}
+
+// Close removes the menu, so key events of its buttons get emitted again. The
+// buttons keep their images until they get replaced.
+func (m *Menu) Close() {
+	for _, remove := range m.remove {
+		remove()
+	}
+}
+
+// SetItems replaces the items of the menu and shows its first page.
+func (m *Menu) SetItems(items []MenuItem) error {
+	m.mu.Lock()
+	m.items = items
+	m.page = 0
+	m.mu.Unlock()
+
+	return m.render()
+}
+
+// Page returns the index of the page currently shown.
+func (m *Menu) Page() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.page
+}
+
+// PageCount returns the number of pages of the menu.
+func (m *Menu) PageCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.pageCount()
+}
+
+// SetPage shows the page with the given index.
+func (m *Menu) SetPage(page int) error {
+	m.mu.Lock()
+	if page < 0 {
+		page = 0
+	}
+	if page >= m.pageCount() {
+		page = m.pageCount() - 1
+	}
+	m.page = page
+	m.mu.Unlock()
+
+	return m.render()
+}
+
+func (m *Menu) pageCount() int {
+	if len(m.items) == 0 {
+		return 1
+	}
+	return (len(m.items) + len(m.keys) - 1) / len(m.keys)
+}
+
+// render shows the current page of the menu.
+func (m *Menu) render() error {
+	m.mu.Lock()
+	items := m.items
+	page := m.page
+	pages := m.pageCount()
+	m.mu.Unlock()
+
+	size := int(m.d.Pixels)
+	theme := m.d.Theme()
+	for slot, key := range m.keys {
+		var img image.Image
+		var err error
+
+		i := page*len(m.keys) + slot
+		switch {
+		case i >= len(items):
+			img, err = renderLabel(size, "", theme)
+		case items[i].Icon != nil:
+			img = scaleImage(items[i].Icon, size, size)
+		default:
+			img, err = renderLabel(size, items[i].Label, theme)
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := m.d.SetImage(key, img); err != nil {
+			return err
+		}
+	}
+
+	paging := []struct {
+		key   uint8
+		label string
+		show  bool
+	}{
+		{m.prev, "←", page > 0},
+		{m.next, "→", page < pages-1},
+	}
+	for _, p := range paging {
+		label := p.label
+		if !p.show {
+			label = ""
+		}
+
+		img, err := renderLabel(size, label, theme)
+		if err != nil {
+			return err
+		}
+		if err := m.d.SetImage(p.key, img); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// handleKey is the middleware handling paging and selection.
+func (m *Menu) handleKey(k Key) (Key, bool) {
+	switch k.Index {
+	case m.prev:
+		if k.Pressed {
+			_ = m.SetPage(m.Page() - 1)
+		}
+		return k, false
+	case m.next:
+		if k.Pressed {
+			_ = m.SetPage(m.Page() + 1)
+		}
+		return k, false
+	}
+
+	for slot, key := range m.keys {
+		if key != k.Index {
+			continue
+		}
+
+		if k.Pressed {
+			m.mu.Lock()
+			i := m.page*len(m.keys) + slot
+			var item MenuItem
+			valid := i < len(m.items)
+			if valid {
+				item = m.items[i]
+			}
+			m.mu.Unlock()
+
+			if valid && m.onSelect != nil {
+				m.onSelect(i, item)
+			}
+		}
+		return k, false
+	}
+
+	return k, true
+}
@@ -0,0 +1,54 @@
+package streamdeck
+
+// Middleware gets called for every key event before it is emitted. It can
+// modify the event, or return false to drop it.
+type Middleware func(Key) (Key, bool)
+
+// middlewareEntry is a registered middleware, identified by its ID so it can
+// be removed again.
+type middlewareEntry struct {
+	id uint64
+	m  Middleware
+}
+
+// Use registers middleware on the key event stream, e.g. for logging,
+// filtering, remapping key indices or implementing custom gestures.
+// Middleware runs in the order it was registered. It can be registered while
+// key events are being read, and the returned function removes it again.
+func (d *Device) Use(m Middleware) func() {
+	d.hooksMutex.Lock()
+	defer d.hooksMutex.Unlock()
+
+	d.hookID++
+	id := d.hookID
+	d.middleware = append(d.middleware, middlewareEntry{id: id, m: m})
+
+	return func() {
+		d.hooksMutex.Lock()
+		defer d.hooksMutex.Unlock()
+
+		for i, e := range d.middleware {
+			if e.id == id {
+				d.middleware = append(d.middleware[:i:i], d.middleware[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// applyMiddleware runs the given key event through all registered middleware.
+// It returns false if the event got dropped.
+func (d *Device) applyMiddleware(key Key) (Key, bool) {
+	// middleware may register or remove middleware, so don't hold the lock
+	d.hooksMutex.RLock()
+	middleware := d.middleware
+	d.hooksMutex.RUnlock()
+
+	for _, e := range middleware {
+		var ok bool
+		if key, ok = e.m(key); !ok {
+			return key, false
+		}
+	}
+	return key, true
+}
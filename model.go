@@ -0,0 +1,67 @@
+package streamdeck
+
+import "fmt"
+
+// Model identifies a Stream Deck model.
+type Model int
+
+// Stream Deck models.
+const (
+	ModelUnknown Model = iota
+	ModelStreamDeck
+	ModelStreamDeckV2
+	ModelStreamDeckMK2
+	ModelStreamDeckMini
+	ModelStreamDeckMiniMK2
+	ModelStreamDeckXL
+)
+
+// String returns the product name of the model.
+func (m Model) String() string {
+	switch m {
+	case ModelStreamDeck:
+		return "Stream Deck"
+	case ModelStreamDeckV2:
+		return "Stream Deck V2"
+	case ModelStreamDeckMK2:
+		return "Stream Deck MK.2"
+	case ModelStreamDeckMini:
+		return "Stream Deck Mini"
+	case ModelStreamDeckMiniMK2:
+		return "Stream Deck Mini MK.2"
+	case ModelStreamDeckXL:
+		return "Stream Deck XL"
+	default:
+		return "Unknown Stream Deck"
+	}
+}
+
+// Model returns the model of the device.
+func (d Device) Model() Model {
+	switch d.info.ProductID {
+	case PID_STREAMDECK:
+		return ModelStreamDeck
+	case PID_STREAMDECK_V2:
+		return ModelStreamDeckV2
+	case PID_STREAMDECK_MK2:
+		return ModelStreamDeckMK2
+	case PID_STREAMDECK_MINI:
+		return ModelStreamDeckMini
+	case PID_STREAMDECK_MINI_MK2:
+		return ModelStreamDeckMiniMK2
+	case PID_STREAMDECK_XL:
+		return ModelStreamDeckXL
+	default:
+		return ModelUnknown
+	}
+}
+
+// ProductID returns the USB product ID of the device.
+func (d Device) ProductID() uint16 {
+	return d.info.ProductID
+}
+
+// String returns a human readable description of the device.
+func (d Device) String() string {
+	return fmt.Sprintf("%s (serial %s)", d.Model(), d.Serial)
+}
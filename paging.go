@@ -0,0 +1,24 @@
+package streamdeck
+
+// Paginate splits a payload into pages of at most pageSize bytes, all but the
+// last one being full. An empty payload results in a single empty page, as
+// every write to the device consists of at least one page. pageSize must be
+// positive.
+func Paginate(payload []byte, pageSize int) [][]byte {
+	if pageSize <= 0 {
+		panic("streamdeck: page size must be positive")
+	}
+	if len(payload) == 0 {
+		return [][]byte{{}}
+	}
+
+	pages := make([][]byte, 0, (len(payload)+pageSize-1)/pageSize)
+	for offset := 0; offset < len(payload); offset += pageSize {
+		end := offset + pageSize
+		if end > len(payload) {
+			end = len(payload)
+		}
+		pages = append(pages, payload[offset:end])
+	}
+	return pages
+}
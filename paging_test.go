@@ -0,0 +1,160 @@
+package streamdeck
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPaginate(t *testing.T) {
+	tests := []struct {
+		name     string
+		length   int
+		pageSize int
+		pages    []int
+	}{
+		{"empty payload", 0, 10, []int{0}},
+		{"smaller than a page", 5, 10, []int{5}},
+		{"exactly one page", 10, 10, []int{10}},
+		{"exact multiple", 30, 10, []int{10, 10, 10}},
+		{"one byte over a multiple", 31, 10, []int{10, 10, 10, 1}},
+		{"one byte short of a multiple", 29, 10, []int{10, 10, 9}},
+		{"single byte pages", 3, 1, []int{1, 1, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := make([]byte, tt.length)
+			for i := range payload {
+				payload[i] = byte(i)
+			}
+
+			pages := Paginate(payload, tt.pageSize)
+			if len(pages) != len(tt.pages) {
+				t.Fatalf("expected %d pages, got %d", len(tt.pages), len(pages))
+			}
+
+			var joined []byte
+			for i, page := range pages {
+				if len(page) != tt.pages[i] {
+					t.Errorf("page %d: expected %d bytes, got %d", i, tt.pages[i], len(page))
+				}
+				joined = append(joined, page...)
+			}
+			if !bytes.Equal(joined, payload) {
+				t.Error("pages don't add up to the payload")
+			}
+		})
+	}
+}
+
+func TestPaginateInvalidPageSize(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for a page size of 0")
+		}
+	}()
+	Paginate([]byte{1}, 0)
+}
+
+type writtenPage struct {
+	page          int
+	lastPage      bool
+	payloadLength int
+}
+
+// writePages writes a payload of the given length in dry-run mode and returns
+// the pages the header callback got called for.
+func writePages(t *testing.T, length, pageSize, headerSize int) []writtenPage {
+	t.Helper()
+
+	d := Device{
+		imagePageSize:       pageSize,
+		imagePageHeaderSize: headerSize,
+		dryRun:              true,
+	}
+
+	var pages []writtenPage
+	err := d.writePaged(make([]byte, length), func(page int, lastPage bool, payloadLength int) []byte {
+		pages = append(pages, writtenPage{page, lastPage, payloadLength})
+		return rev2ImagePageHeader(page, 0, payloadLength, lastPage)[:headerSize]
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pages
+}
+
+func TestWritePaged(t *testing.T) {
+	// a page of 1024 bytes with a header of 8 bytes leaves 1016 payload bytes
+	const pageSize, headerSize, capacity = 1024, 8, 1016
+
+	tests := []struct {
+		name   string
+		length int
+		pages  []int
+	}{
+		{"empty payload", 0, []int{0}},
+		{"fills the page including its header", pageSize, []int{capacity, pageSize - capacity}},
+		{"exactly one page", capacity, []int{capacity}},
+		{"exact multiple", 3 * capacity, []int{capacity, capacity, capacity}},
+		{"one byte over a multiple", 2*capacity + 1, []int{capacity, capacity, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pages := writePages(t, tt.length, pageSize, headerSize)
+			if len(pages) != len(tt.pages) {
+				t.Fatalf("expected %d pages, got %d", len(tt.pages), len(pages))
+			}
+
+			for i, p := range pages {
+				if p.page != i {
+					t.Errorf("expected page index %d, got %d", i, p.page)
+				}
+				if p.payloadLength != tt.pages[i] {
+					t.Errorf("page %d: expected %d payload bytes, got %d", i, tt.pages[i], p.payloadLength)
+				}
+				if last := i == len(pages)-1; p.lastPage != last {
+					t.Errorf("page %d: expected last page %v, got %v", i, last, p.lastPage)
+				}
+			}
+		})
+	}
+}
+
+func TestWritePagedReadOnly(t *testing.T) {
+	d := Device{
+		imagePageSize:       1024,
+		imagePageHeaderSize: 8,
+		readOnly:            true,
+	}
+
+	err := d.writePaged([]byte{1}, func(int, bool, int) []byte { return nil })
+	if err != ErrReadOnly {
+		t.Errorf("expected ErrReadOnly, got %v", err)
+	}
+}
+
+func TestWritePagedHeaderSize(t *testing.T) {
+	d := Device{
+		imagePageSize:       1024,
+		imagePageHeaderSize: 8,
+		dryRun:              true,
+	}
+
+	for _, size := range []int{0, 7, 9} {
+		err := d.writePaged([]byte{1}, func(int, bool, int) []byte { return make([]byte, size) })
+		if err == nil {
+			t.Errorf("expected an error for a header of %d bytes", size)
+		}
+	}
+}
+
+func BenchmarkPaginate(b *testing.B) {
+	// a JPEG encoded 96x96 image, split into the pages of a Stream Deck XL
+	payload := make([]byte, 8*1024)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Paginate(payload, 1024-8)
+	}
+}
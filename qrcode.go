@@ -0,0 +1,24 @@
+package streamdeck
+
+import (
+	"fmt"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// SetQRCode renders the given content as a QR code and sets it as the image
+// of a button on the Stream Deck. The QR code is generated at the native
+// resolution of the device, so it stays scannable.
+func (d Device) SetQRCode(index uint8, content string) error {
+	q, err := qrcode.New(content, qrcode.Medium)
+	if err != nil {
+		return fmt.Errorf("cannot encode QR code: %v", err)
+	}
+
+	img := q.Image(int(d.Pixels))
+	if img.Bounds().Dx() > int(d.Pixels) {
+		return fmt.Errorf("content is too long for a QR code of %[1]dx%[1]d pixels", d.Pixels)
+	}
+
+	return d.SetImage(index, img)
+}
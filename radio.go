@@ -0,0 +1,140 @@
+package streamdeck
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"sync"
+
+	"golang.org/x/image/draw"
+)
+
+// RadioGroup manages a set of buttons as mutually exclusive options: pressing
+// one of them selects it and highlights it, while the others get
+// de-highlighted.
+type RadioGroup struct {
+	d        *Device
+	keys     []uint8
+	imgs     []image.Image
+	onChange func(int)
+	remove   []func()
+
+	mu       sync.Mutex
+	selected int
+}
+
+// NewRadioGroup creates a radio group on the given buttons, showing the given
+// images, with no option selected. onChange gets called with the position of
+// the newly selected option within keys, whenever a press changes the
+// selection. Key events of the buttons are consumed and not emitted, until
+// the group gets closed.
+func (d *Device) NewRadioGroup(keys []uint8, imgs []image.Image, onChange func(int)) (*RadioGroup, error) {
+	if len(keys) != len(imgs) {
+		return nil, fmt.Errorf("radio group has %d keys but %d images", len(keys), len(imgs))
+	}
+
+	g := &RadioGroup{
+		d:        d,
+		keys:     keys,
+		imgs:     imgs,
+		onChange: onChange,
+		selected: -1,
+	}
+	for i, key := range keys {
+		if err := d.SetImage(key, imgs[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	g.remove = []func(){
+		d.Use(g.handleKey),
+		d.onThemeChange(func() error {
+			return g.Select(g.Selected())
+		}),
+	}
+	return g, nil
+}
+
+// Close removes the radio group, so key events of its buttons get emitted
+// again. The buttons keep their images until they get replaced.
+func (g *RadioGroup) Close() {
+	for _, remove := range g.remove {
+		remove()
+	}
+}
+
+// Selected returns the position of the selected option within the group's
+// keys, or -1 if no option is selected.
+func (g *RadioGroup) Selected() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.selected
+}
+
+// Select selects the option at the given position and updates the images,
+// without calling onChange. A position of -1 clears the selection.
+func (g *RadioGroup) Select(selected int) error {
+	if selected < -1 || selected >= len(g.keys) {
+		return fmt.Errorf("radio group has no option %d", selected)
+	}
+
+	g.mu.Lock()
+	prev := g.selected
+	g.selected = selected
+	g.mu.Unlock()
+
+	if prev >= 0 && prev != selected {
+		if err := g.d.SetImage(g.keys[prev], g.imgs[prev]); err != nil {
+			return err
+		}
+	}
+	if selected >= 0 {
+		return g.d.SetImage(g.keys[selected], highlight(g.imgs[selected], g.d.Theme().Accent))
+	}
+	return nil
+}
+
+// handleKey is the middleware changing the selection on button presses.
+func (g *RadioGroup) handleKey(k Key) (Key, bool) {
+	for i, key := range g.keys {
+		if key != k.Index {
+			continue
+		}
+
+		if k.Pressed && i != g.Selected() {
+			_ = g.Select(i)
+			if g.onChange != nil {
+				g.onChange(i)
+			}
+		}
+		return k, false
+	}
+
+	return k, true
+}
+
+// highlight returns the given image with a border in the given color.
+func highlight(img image.Image, c color.Color) image.Image {
+	b := img.Bounds()
+	out := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Copy(out, image.Point{}, img, b, draw.Src, nil)
+
+	width := b.Dx() / 16
+	if width < 1 {
+		width = 1
+	}
+
+	fill := image.NewUniform(c)
+	r := out.Bounds()
+	for _, edge := range []image.Rectangle{
+		image.Rect(r.Min.X, r.Min.Y, r.Max.X, r.Min.Y+width),
+		image.Rect(r.Min.X, r.Max.Y-width, r.Max.X, r.Max.Y),
+		image.Rect(r.Min.X, r.Min.Y, r.Min.X+width, r.Max.Y),
+		image.Rect(r.Max.X-width, r.Min.Y, r.Max.X, r.Max.Y),
+	} {
+		draw.Draw(out, edge, fill, image.Point{}, draw.Src)
+	}
+
+	return out
+}
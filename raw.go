@@ -0,0 +1,31 @@
+package streamdeck
+
+// SendRawFeatureReport sends a raw feature report to the device. The payload
+// starts with the report ID and gets padded to the device's feature report
+// size.
+//
+// This is an advanced API meant for experimenting with undocumented commands:
+// sending unknown reports can put the device into an unexpected state.
+func (d Device) SendRawFeatureReport(payload []byte) error {
+	return d.sendFeatureReport(payload)
+}
+
+// GetRawFeatureReport requests the feature report with the given ID from the
+// device and returns it, including the report ID.
+//
+// This is an advanced API meant for experimenting with undocumented commands.
+func (d Device) GetRawFeatureReport(id byte) ([]byte, error) {
+	return d.getFeatureReport([]byte{id})
+}
+
+// WritePaged writes a raw payload to the device, split into pages like image
+// data: every page is as long as the device's image pages and starts with the
+// header returned for it, followed by its part of the payload. Pages have room
+// for as much payload as an image page, so headers must be exactly as long as
+// the device's image page headers.
+//
+// This is an advanced API meant for driving surfaces the library doesn't
+// support yet.
+func (d Device) WritePaged(header func(page int, lastPage bool, payloadLength int) []byte, payload []byte) error {
+	return d.writePaged(payload, header)
+}
@@ -0,0 +1,68 @@
+package streamdeck
+
+import "fmt"
+
+// SetKeyMap sets a remapping table for button indices, e.g. for devices that
+// are mounted rotated. The keys of the map are the indices used by the
+// application, the values the indices of the physical buttons. The table
+// applies to key events as well as to all methods taking a button index.
+// Buttons missing from the table keep their index, and every physical button
+// must be mapped to exactly once. A nil map removes the remapping.
+func (d *Device) SetKeyMap(m map[uint8]uint8) error {
+	var keyMap, reverseKeyMap map[uint8]uint8
+	if m != nil {
+		keyMap = make(map[uint8]uint8, len(m))
+		reverseKeyMap = make(map[uint8]uint8, len(m))
+		for logical, physical := range m {
+			if logical >= d.Keys || physical >= d.Keys {
+				return fmt.Errorf("key map entry %d -> %d out of range, device has %d keys", logical, physical, d.Keys)
+			}
+			keyMap[logical] = physical
+			reverseKeyMap[physical] = logical
+		}
+
+		// buttons missing from the table keep their index, so the resulting
+		// mapping of all buttons must be one-to-one
+		mapped := make(map[uint8]uint8, d.Keys)
+		for logical := uint8(0); logical < d.Keys; logical++ {
+			physical, ok := keyMap[logical]
+			if !ok {
+				physical = logical
+			}
+			if other, ok := mapped[physical]; ok {
+				return fmt.Errorf("keys %d and %d are both mapped to button %d", other, logical, physical)
+			}
+			mapped[physical] = logical
+		}
+	}
+
+	d.hooksMutex.Lock()
+	defer d.hooksMutex.Unlock()
+
+	d.keyMap = keyMap
+	d.reverseKeyMap = reverseKeyMap
+	return nil
+}
+
+// physicalKey returns the index of the physical button for the given index.
+func (d *Device) physicalKey(index uint8) uint8 {
+	d.hooksMutex.RLock()
+	defer d.hooksMutex.RUnlock()
+
+	if physical, ok := d.keyMap[index]; ok {
+		return physical
+	}
+	return index
+}
+
+// logicalKey returns the index the application uses for the given physical
+// button.
+func (d *Device) logicalKey(index uint8) uint8 {
+	d.hooksMutex.RLock()
+	defer d.hooksMutex.RUnlock()
+
+	if logical, ok := d.reverseKeyMap[index]; ok {
+		return logical
+	}
+	return index
+}
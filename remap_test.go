@@ -0,0 +1,52 @@
+package streamdeck
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestSetKeyMap(t *testing.T) {
+	tests := []struct {
+		name  string
+		m     map[uint8]uint8
+		valid bool
+	}{
+		{"nil map", nil, true},
+		{"swapped keys", map[uint8]uint8{0: 1, 1: 0}, true},
+		{"physical key out of range", map[uint8]uint8{0: 6}, false},
+		{"logical key out of range", map[uint8]uint8{6: 0}, false},
+		{"two keys on one button", map[uint8]uint8{0: 1, 2: 1}, false},
+		{"unmapped key on a mapped button", map[uint8]uint8{0: 1}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Device{Keys: 6, hooksMutex: &sync.RWMutex{}}
+			err := d.SetKeyMap(tt.m)
+			if tt.valid && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !tt.valid {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				if d.keyMap != nil || d.reverseKeyMap != nil {
+					t.Error("invalid key map got applied")
+				}
+			}
+		})
+	}
+}
+
+func TestKeyMapRoundTrip(t *testing.T) {
+	d := &Device{Keys: 6, hooksMutex: &sync.RWMutex{}}
+	if err := d.SetKeyMap(map[uint8]uint8{0: 5, 5: 0}); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := uint8(0); i < d.Keys; i++ {
+		if l := d.logicalKey(d.physicalKey(i)); l != i {
+			t.Errorf("key %d maps back to %d", i, l)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package streamdeck
+
+import (
+	"image"
+	"image/color"
+
+	"golang.org/x/image/draw"
+)
+
+// PostProcessing holds the parameters of the post-processing SetImageScaled
+// applies after downscaling an image, so small icons stay legible.
+type PostProcessing struct {
+	// Sharpen is the amount of the unsharp mask, 0 disables sharpening.
+	Sharpen float64
+	// Contrast is the contrast boost, e.g. 0.2 for 20% more contrast. 0
+	// disables the contrast boost.
+	Contrast float64
+}
+
+// SetPostProcessing sets the post-processing SetImageScaled applies after
+// downscaling an image.
+func (d *Device) SetPostProcessing(p PostProcessing) {
+	d.postProcessing = p
+}
+
+// SetImageScaled sets the image of a button on the Stream Deck, scaling the
+// image to the correct resolution for the device first. The index starts with
+// 0 being the top-left button.
+func (d Device) SetImageScaled(index uint8, img image.Image) error {
+	if img.Bounds().Dx() != int(d.Pixels) || img.Bounds().Dy() != int(d.Pixels) {
+		img = scaleImage(img, int(d.Pixels), int(d.Pixels))
+		img = postProcess(img, d.postProcessing)
+	}
+
+	return d.SetImage(index, img)
+}
+
+// writeTiled scales the given image to the size of the entire deck and writes
+// the matching part of it to every button, without remembering them.
+func (d Device) writeTiled(img image.Image) error {
+	for i, key := range d.tiles(img) {
+		if err := d.writeImage(uint8(i), key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// tiles scales the given image to the size of the entire deck and returns the
+// matching part of it for every button.
+func (d Device) tiles(img image.Image) []image.Image {
+	tile := scaleImage(img, int(d.Pixels)*int(d.Columns), int(d.Pixels)*int(d.Rows))
+
+	keys := make([]image.Image, d.Keys)
+	for i := uint8(0); i < d.Keys; i++ {
+		p := d.physicalKey(i)
+		x := int(p%d.Columns) * int(d.Pixels)
+		y := int(p/d.Columns) * int(d.Pixels)
+
+		key := image.NewRGBA(image.Rect(0, 0, int(d.Pixels), int(d.Pixels)))
+		draw.Copy(key, image.Point{}, tile, image.Rect(x, y, x+int(d.Pixels), y+int(d.Pixels)), draw.Src, nil)
+		keys[i] = key
+	}
+
+	return keys
+}
+
+// scaleImage returns the given image scaled to width x height pixels.
+func scaleImage(img image.Image, width, height int) image.Image {
+	if img.Bounds().Dx() == width && img.Bounds().Dy() == height {
+		return img
+	}
+
+	scaled := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(scaled, scaled.Bounds(), img, img.Bounds(), draw.Src, nil)
+	return scaled
+}
+
+// postProcess returns the given image sharpened and contrast-boosted with the
+// given parameters.
+func postProcess(img image.Image, p PostProcessing) image.Image {
+	if p.Sharpen == 0 && p.Contrast == 0 {
+		return img
+	}
+
+	rgba := toRGBA(img)
+	b := rgba.Bounds()
+	out := image.NewRGBA(b)
+
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := rgba.RGBAAt(x, y)
+			blur := boxBlurAt(rgba, x, y)
+
+			var v [3]float64
+			for ch, orig := range []uint8{c.R, c.G, c.B} {
+				// unsharp mask: add the difference to the blurred image
+				v[ch] = float64(orig) + p.Sharpen*(float64(orig)-blur[ch])
+				v[ch] = (v[ch]-128)*(1+p.Contrast) + 128
+			}
+
+			out.SetRGBA(x, y, color.RGBA{clamp(v[0]), clamp(v[1]), clamp(v[2]), c.A})
+		}
+	}
+	return out
+}
+
+// boxBlurAt returns the average red, green and blue values of the 3x3 pixels
+// around the given coordinates.
+func boxBlurAt(img *image.RGBA, x, y int) [3]float64 {
+	var sum [3]float64
+	var n float64
+
+	b := img.Bounds()
+	for yy := y - 1; yy <= y+1; yy++ {
+		for xx := x - 1; xx <= x+1; xx++ {
+			if !(image.Point{xx, yy}).In(b) {
+				continue
+			}
+
+			c := img.RGBAAt(xx, yy)
+			sum[0] += float64(c.R)
+			sum[1] += float64(c.G)
+			sum[2] += float64(c.B)
+			n++
+		}
+	}
+
+	return [3]float64{sum[0] / n, sum[1] / n, sum[2] / n}
+}
+
+// clamp returns the given value clamped to the range of a color channel.
+func clamp(v float64) uint8 {
+	if v < 0 {
+		return 0
+	}
+	if v > 255 {
+		return 255
+	}
+	return uint8(v + 0.5)
+}
@@ -0,0 +1,225 @@
+package streamdeck
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ScreensaverMode defines how the screensaver lays out its images on the
+// keys.
+type ScreensaverMode int
+
+// Screensaver modes.
+const (
+	// ScreensaverPerKey shows a different image on every key.
+	ScreensaverPerKey ScreensaverMode = iota
+	// ScreensaverTiled spreads each image across the entire deck.
+	ScreensaverTiled
+)
+
+// SetScreensaver starts a slideshow of the images found in dir, once no key
+// events have been received for the given timeout. The images change every
+// interval, fading the device out and back in. Any key press stops the
+// screensaver and restores the previously set key images. A timeout of 0
+// disables the screensaver.
+//
+// Only images in formats registered with the image package can be shown, so
+// import the decoders needed, e.g. image/png.
+func (d *Device) SetScreensaver(dir string, mode ScreensaverMode, timeout, interval time.Duration) error {
+	d.cancelScreensaver()
+	if timeout == 0 {
+		return nil
+	}
+	if interval <= 0 {
+		return fmt.Errorf("screensaver interval must be positive, got %s", interval)
+	}
+
+	imgs, err := loadImages(dir)
+	if err != nil {
+		return err
+	}
+	if len(imgs) == 0 {
+		return fmt.Errorf("no images found in %s", dir)
+	}
+
+	var ctx context.Context
+	ctx, d.screensaverCancel = context.WithCancel(context.Background())
+
+	go func() {
+		var frame int
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+
+		for {
+			select {
+			case <-timer.C:
+				d.sleepMutex.Lock()
+				idle := time.Since(d.lastActionTime)
+				if d.asleep {
+					// waking up restarts the idle time
+					d.sleepMutex.Unlock()
+					timer.Reset(timeout)
+					continue
+				}
+				if idle < timeout {
+					// check again once the device could be idle long enough
+					d.sleepMutex.Unlock()
+					timer.Reset(timeout - idle)
+					continue
+				}
+				if !d.screensaverActive {
+					d.screensaverActive = true
+					d.screensaverBrightness = d.brightness
+				}
+				d.sleepMutex.Unlock()
+
+				_ = d.showScreensaverFrame(ctx, imgs, mode, frame)
+				frame++
+				timer.Reset(interval)
+
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+// ScreensaverActive returns true if the screensaver is currently running.
+func (d Device) ScreensaverActive() bool {
+	if d.sleepMutex == nil {
+		return false
+	}
+
+	d.sleepMutex.RLock()
+	defer d.sleepMutex.RUnlock()
+	return d.screensaverActive
+}
+
+func (d *Device) cancelScreensaver() {
+	if d.screensaverCancel == nil {
+		return
+	}
+
+	d.screensaverCancel()
+	d.screensaverCancel = nil
+	_ = d.stopScreensaver()
+}
+
+// stopScreensaver stops a running screensaver, cancelling the fades of the
+// current frame, and restores the key images and brightness.
+func (d *Device) stopScreensaver() error {
+	// keep the current frame from being written while restoring the images
+	d.screensaverMutex.Lock()
+	defer d.screensaverMutex.Unlock()
+
+	d.sleepMutex.Lock()
+	if !d.screensaverActive {
+		d.sleepMutex.Unlock()
+		return nil
+	}
+	d.screensaverActive = false
+	d.lastActionTime = time.Now()
+	d.resetSleepTimer()
+	brightness := d.screensaverBrightness
+	d.sleepMutex.Unlock()
+
+	d.cancelFades()
+	if err := d.Redraw(); err != nil {
+		return err
+	}
+	return d.SetBrightness(brightness)
+}
+
+// showScreensaverFrame fades out the device, shows the given frame of the
+// slideshow and fades back in. It stops as soon as the screensaver gets
+// stopped, so the frame doesn't overwrite the restored key images.
+func (d *Device) showScreensaverFrame(ctx context.Context, imgs []image.Image, mode ScreensaverMode, frame int) error {
+	stopped := func() bool {
+		return ctx.Err() != nil || !d.ScreensaverActive()
+	}
+
+	d.sleepMutex.RLock()
+	brightness := d.screensaverBrightness
+	d.sleepMutex.RUnlock()
+
+	if cancelled, err := d.fade(brightness, 0, d.fadeDuration); cancelled || err != nil {
+		return err
+	}
+	if stopped() {
+		return nil
+	}
+
+	var keys []image.Image
+	switch mode {
+	case ScreensaverTiled:
+		keys = d.tiles(imgs[frame%len(imgs)])
+
+	default:
+		keys = make([]image.Image, d.Keys)
+		for i := range keys {
+			keys[i] = scaleImage(imgs[(frame+i)%len(imgs)], int(d.Pixels), int(d.Pixels))
+		}
+	}
+
+	for i, img := range keys {
+		if written, err := d.writeScreensaverImage(uint8(i), img, stopped); !written || err != nil {
+			return err
+		}
+	}
+
+	if cancelled, err := d.fade(0, brightness, d.fadeDuration); cancelled || err != nil || stopped() {
+		return err
+	}
+	return d.setBrightness(brightness)
+}
+
+// writeScreensaverImage writes an image of the screensaver to a button, unless
+// the screensaver got stopped. The check and the write happen under the
+// screensaver mutex, so stopScreensaver can't restore the button's image in
+// between. It returns false if the screensaver got stopped.
+func (d *Device) writeScreensaverImage(index uint8, img image.Image, stopped func() bool) (bool, error) {
+	d.screensaverMutex.Lock()
+	defer d.screensaverMutex.Unlock()
+
+	if stopped() {
+		return false, nil
+	}
+	return true, d.writeImage(index, img)
+}
+
+// loadImages decodes all images found in a directory.
+func loadImages(dir string) ([]image.Image, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var imgs []image.Image
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+
+		f, err := os.Open(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		img, _, err := image.Decode(f)
+		_ = f.Close()
+		if err != nil {
+			// skip files that aren't images
+			continue
+		}
+		imgs = append(imgs, img)
+	}
+
+	return imgs, nil
+}
@@ -0,0 +1,48 @@
+package streamdeck
+
+import (
+	"image"
+	"time"
+)
+
+// CloseAction defines what is left on the device when it gets closed.
+type CloseAction int
+
+// Close actions.
+const (
+	// CloseKeep leaves the current images on the device.
+	CloseKeep CloseAction = iota
+	// CloseClear sets a black image on all buttons.
+	CloseClear
+	// CloseReset resets the device, showing the standby image.
+	CloseReset
+)
+
+// SetSplash sets images that are shown across the entire deck when the device
+// gets opened. Multiple images are played as an animation, showing each frame
+// for the given delay.
+func (d *Device) SetSplash(delay time.Duration, frames ...image.Image) {
+	d.splash = frames
+	d.splashDelay = delay
+}
+
+// SetCloseAction sets what is left on the device when it gets closed, instead
+// of leaving the current images in place.
+func (d *Device) SetCloseAction(a CloseAction) {
+	d.closeAction = a
+}
+
+// showSplash plays the splash images on the device.
+func (d Device) showSplash() error {
+	for i, frame := range d.splash {
+		if err := d.writeTiled(frame); err != nil {
+			return err
+		}
+
+		if i < len(d.splash)-1 {
+			time.Sleep(d.splashDelay)
+		}
+	}
+
+	return nil
+}
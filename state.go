@@ -0,0 +1,157 @@
+package streamdeck
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// state is the on-disk representation of a device's state.
+type state struct {
+	Brightness *uint8            `json:"brightness,omitempty"`
+	Images     map[uint8]string  `json:"images,omitempty"`
+	Dims       map[uint8]float64 `json:"dims,omitempty"`
+	Disabled   []uint8           `json:"disabled,omitempty"`
+}
+
+// SaveState saves the state of the device to the directory at path: its
+// brightness, the images set on its buttons and their dim levels and enabled
+// state. The images get stored as PNG files. The device can't report its
+// brightness, so it only gets saved if it was set before.
+func (d Device) SaveState(path string) error {
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return err
+	}
+
+	s := state{
+		Images: make(map[uint8]string),
+		Dims:   make(map[uint8]float64),
+	}
+	if d.brightnessKnown {
+		brightness := d.brightness
+		if d.asleep {
+			brightness = d.preSleepBrightness
+		}
+		s.Brightness = &brightness
+	}
+
+	for i := uint8(0); i < d.Keys; i++ {
+		if img := d.Image(i); img != nil {
+			name := fmt.Sprintf("key-%d.png", i)
+			if err := savePNG(filepath.Join(path, name), img); err != nil {
+				return err
+			}
+			s.Images[i] = name
+		}
+		if level := d.KeyDim(i); level != 1 {
+			s.Dims[i] = level
+		}
+		if !d.KeyEnabled(i) {
+			s.Disabled = append(s.Disabled, i)
+		}
+	}
+
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(path, "state.json"), b, 0644)
+}
+
+// LoadState restores a device state, previously saved with SaveState, from
+// the directory at path. Buttons missing from the saved dim levels and
+// disabled buttons get restored to full brightness and enabled. The
+// brightness is left alone if it wasn't saved.
+func (d *Device) LoadState(path string) error {
+	b, err := ioutil.ReadFile(filepath.Join(path, "state.json"))
+	if err != nil {
+		return err
+	}
+
+	var s state
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("cannot parse device state: %v", err)
+	}
+
+	dims := make(map[uint8]float64, len(s.Dims))
+	for i, level := range s.Dims {
+		if level < 0 {
+			level = 0
+		}
+		if level < 1 {
+			dims[i] = level
+		}
+	}
+	disabled := make(map[uint8]bool, len(s.Disabled))
+	for _, i := range s.Disabled {
+		disabled[i] = true
+	}
+
+	// replace the dim levels and disabled buttons instead of adding to them,
+	// and remember which buttons need to be redrawn
+	changed := make(map[uint8]bool)
+	d.imagesMutex.Lock()
+	for i := uint8(0); i < d.Keys; i++ {
+		if d.dims[i] != dims[i] || d.disabled[i] != disabled[i] {
+			changed[i] = true
+		}
+	}
+	d.dims = dims
+	d.disabled = disabled
+	d.imagesMutex.Unlock()
+
+	for i := uint8(0); i < d.Keys; i++ {
+		if name, ok := s.Images[i]; ok {
+			img, err := loadPNG(filepath.Join(path, name))
+			if err != nil {
+				return err
+			}
+			if err := d.SetImage(i, img); err != nil {
+				return err
+			}
+			continue
+		}
+
+		img := d.Image(i)
+		if !changed[i] || img == nil || d.ScreensaverActive() {
+			continue
+		}
+		if err := d.writeImage(i, d.keyImage(i, img)); err != nil {
+			return err
+		}
+	}
+
+	if s.Brightness == nil {
+		return nil
+	}
+	return d.SetBrightness(*s.Brightness)
+}
+
+// savePNG writes an image as PNG file.
+func savePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := png.Encode(f, img); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// loadPNG reads an image from a PNG file.
+func loadPNG(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() //nolint:errcheck // r/o file
+
+	return png.Decode(f)
+}
@@ -0,0 +1,57 @@
+package streamdeck
+
+import "time"
+
+// KeyStats holds the usage statistics of a single button.
+type KeyStats struct {
+	Presses   uint64
+	LastPress time.Time
+}
+
+// EnableStats enables or disables collecting usage statistics for the
+// buttons. Disabling it discards the collected statistics. The device needs
+// to be open.
+func (d *Device) EnableStats(enabled bool) {
+	d.statsMutex.Lock()
+	defer d.statsMutex.Unlock()
+
+	if !enabled {
+		d.stats = nil
+		return
+	}
+	if d.stats == nil {
+		d.stats = make(map[uint8]KeyStats)
+	}
+}
+
+// Stats returns the usage statistics collected since EnableStats was called,
+// indexed by button. Buttons that were never pressed are missing.
+func (d *Device) Stats() map[uint8]KeyStats {
+	d.statsMutex.Lock()
+	defer d.statsMutex.Unlock()
+
+	if d.stats == nil {
+		return nil
+	}
+
+	stats := make(map[uint8]KeyStats, len(d.stats))
+	for k, v := range d.stats {
+		stats[k] = v
+	}
+	return stats
+}
+
+// recordPress counts a press of the given button, if statistics are enabled.
+func (d *Device) recordPress(index uint8) {
+	d.statsMutex.Lock()
+	defer d.statsMutex.Unlock()
+
+	if d.stats == nil {
+		return
+	}
+
+	s := d.stats[index]
+	s.Presses++
+	s.LastPress = time.Now()
+	d.stats[index] = s
+}
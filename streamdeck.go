@@ -3,6 +3,7 @@ package streamdeck
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"image"
 	"image/color"
@@ -18,6 +19,14 @@ import (
 const (
 	// 30 fps fade animation.
 	fadeDelay = time.Second / 30
+	// 10 fps fade animation in low-power mode.
+	lowPowerFadeDelay = time.Second / 10
+
+	// maximum brightness in low-power mode, in percent.
+	lowPowerBrightness = 30
+
+	// reports are discarded for this long after waking up.
+	defaultWakeGracePeriod = 250 * time.Millisecond
 )
 
 // Stream Deck Vendor & Product IDs.
@@ -46,6 +55,14 @@ var (
 	c_REV2_BRIGHTNESS = []byte{0x03, 0x08}
 )
 
+// ErrDeviceClosed is returned when trying to communicate with a device that
+// is not open.
+var ErrDeviceClosed = errors.New("device is closed")
+
+// ErrReadOnly is returned when trying to write to a device opened with
+// OpenReadOnly.
+var ErrReadOnly = errors.New("device is opened read-only")
+
 // Device represents a single Stream Deck device.
 type Device struct {
 	ID     string
@@ -72,19 +89,77 @@ type Device struct {
 	resetCommand         []byte
 	setBrightnessCommand []byte
 
-	keyState []byte
-
-	device *hid.Device
-	info   hid.DeviceInfo
+	keyState      []byte
+	inputMutex    *sync.Mutex
+	inputDisabled bool
+	unlockKeys    []uint8
+	unlockHold    time.Duration
+	unlockTimer   *time.Timer
+	hooksMutex    *sync.RWMutex
+	hookID        uint64
+	middleware    []middlewareEntry
+	feedback      Feedback
+	eventLog      *eventLog
+	keyMap        map[uint8]uint8
+	reverseKeyMap map[uint8]uint8
+
+	stats      map[uint8]KeyStats
+	statsMutex *sync.Mutex
+
+	subscribers      []*subscriber
+	subscribersMutex *sync.Mutex
+	reading          bool
+	paused           bool
+	pauseMode        PauseMode
+	pendingKeys      []Key
+
+	device     *hid.Device
+	info       hid.DeviceInfo
+	writeMutex *sync.Mutex
+	logger     Logger
+	dryRun     bool
+	readOnly   bool
+	locked     bool
+	transfers  *transferStats
 
 	lastActionTime time.Time
 	asleep         bool
-	sleepCancel    context.CancelFunc
+	sleepTimeout   time.Duration
+	sleepTimer     *time.Timer
 	sleepMutex     *sync.RWMutex
 	fadeDuration   time.Duration
+	wakeGrace      time.Duration
+
+	sleepStateCallback func(asleep bool)
+	sleepHooks         []sleepHookEntry
 
 	brightness         uint8
+	brightnessKnown    bool
 	preSleepBrightness uint8
+	lowPower           bool
+	fadeGeneration     uint64
+	brightnessMutex    *sync.Mutex
+
+	images      map[uint8]image.Image
+	imagesMutex *sync.RWMutex
+	dims        map[uint8]float64
+	disabled    map[uint8]bool
+	background  color.Color
+	dithering   Dithering
+
+	postProcessing PostProcessing
+	theme          *Theme
+	accessibility  bool
+	themed         []themedEntry
+
+	splash      []image.Image
+	splashDelay time.Duration
+	closeAction CloseAction
+
+	screensaverMutex      *sync.Mutex
+	screensaverActive     bool
+	screensaverBrightness uint8
+	screensaverCancel     context.CancelFunc
 }
 
 // Key holds the current status of a key on the device.
@@ -196,11 +271,20 @@ func Devices() ([]Device, error) {
 			}
 		}
 
-		if dev.ID != "" {
-			dev.keyState = make([]byte, dev.Columns*dev.Rows)
-			dev.info = d
-			dd = append(dd, dev)
+		if dev.ID == "" {
+			if d.VendorID == VID_ELGATO && UnknownDeviceHandler != nil {
+				UnknownDeviceHandler(d.ProductID, d.Serial)
+			}
+			continue
 		}
+
+		dev.keyState = make([]byte, dev.Columns*dev.Rows)
+		dev.brightnessMutex = &sync.Mutex{}
+		dev.hooksMutex = &sync.RWMutex{}
+		dev.inputMutex = &sync.Mutex{}
+		dev.screensaverMutex = &sync.Mutex{}
+		dev.info = d
+		dd = append(dd, dev)
 	}
 
 	return dd, nil
@@ -209,17 +293,69 @@ func Devices() ([]Device, error) {
 // Open the device for input/output. This must be called before trying to
 // communicate with the device.
 func (d *Device) Open() error {
+	d.readOnly = false
+	if err := d.open(); err != nil {
+		return err
+	}
+
+	return d.showSplash()
+}
+
+// OpenReadOnly opens the device for reading key events only. All writes, like
+// setting images or the brightness, fail with ErrReadOnly. This allows
+// observing the key events of a device controlled by another application,
+// where the OS allows sharing the device.
+func (d *Device) OpenReadOnly() error {
+	d.readOnly = true
+	return d.open()
+}
+
+func (d *Device) open() error {
 	var err error
 	d.device, err = d.info.Open()
 	d.lastActionTime = time.Now()
+	d.wakeGrace = defaultWakeGracePeriod
+	d.writeMutex = &sync.Mutex{}
+	d.transfers = &transferStats{}
+	d.statsMutex = &sync.Mutex{}
 	d.sleepMutex = &sync.RWMutex{}
+	d.subscribersMutex = &sync.Mutex{}
+	d.images = make(map[uint8]image.Image)
+	d.dims = make(map[uint8]float64)
+	d.disabled = make(map[uint8]bool)
+	d.imagesMutex = &sync.RWMutex{}
 	return err
 }
 
-// Close the connection with the device.
+// Close the connection with the device. Close waits for an image transfer in
+// progress to finish, and leaves the device in the state set with
+// SetCloseAction. It is safe to call Close more than once.
 func (d *Device) Close() error {
+	if d.device == nil {
+		return nil
+	}
+
 	d.cancelSleepTimer()
-	return d.device.Close()
+	d.cancelScreensaver()
+	d.stopUnlockTimer()
+	d.logEvent(EventClose, 0)
+
+	if !d.readOnly {
+		switch d.closeAction {
+		case CloseClear:
+			_ = d.Clear()
+		case CloseReset:
+			_ = d.Reset()
+		}
+	}
+
+	d.writeMutex.Lock()
+	defer d.writeMutex.Unlock()
+
+	err := d.device.Close()
+	d.device = nil
+	_ = d.Unlock()
+	return err
 }
 
 // FirmwareVersion returns the firmware version of the device.
@@ -243,7 +379,7 @@ func (d Device) Clear() error {
 	for i := uint8(0); i <= d.Columns*d.Rows; i++ {
 		err := d.SetImage(i, img)
 		if err != nil {
-			fmt.Println(err)
+			d.logf("cannot clear key %d: %v", i, err)
 			return err
 		}
 	}
@@ -252,75 +388,481 @@ func (d Device) Clear() error {
 }
 
 // ReadKeys returns a channel, which it will use to emit key presses/releases.
+// It can be called multiple times, every channel receives all key events.
 func (d *Device) ReadKeys() (chan Key, error) {
-	kch := make(chan Key)
-	keyBuffer := make([]byte, d.keyStateOffset+len(d.keyState))
-	go func() {
-		for {
-			copy(d.keyState, keyBuffer[d.keyStateOffset:])
+	return d.Subscribe(0)
+}
 
-			if _, err := d.device.Read(keyBuffer); err != nil {
-				close(kch)
-				return
-			}
+// Subscribe returns a new channel with the given buffer size, which will
+// receive all key presses/releases. Every subscriber gets its own copy of the
+// key events, so multiple consumers can read them independently. Key events
+// never get dropped: every subscriber has its own queue, so a slow subscriber
+// doesn't hold up the others, but its queue grows until it catches up. It
+// returns ErrDeviceClosed if the device is not open.
+func (d *Device) Subscribe(buffer int) (chan Key, error) {
+	device := d.device
+	if device == nil {
+		return nil, ErrDeviceClosed
+	}
+	sub := newSubscriber(buffer)
+
+	d.subscribersMutex.Lock()
+	defer d.subscribersMutex.Unlock()
+
+	d.subscribers = append(d.subscribers, sub)
+	if !d.reading {
+		d.reading = true
+		go d.readKeys(device)
+	}
+
+	return sub.ch, nil
+}
+
+// Unsubscribe stops delivering key events to the given channel and closes it,
+// discarding key events that haven't been received yet.
+func (d *Device) Unsubscribe(kch chan Key) {
+	d.subscribersMutex.Lock()
+	defer d.subscribersMutex.Unlock()
+
+	for i, sub := range d.subscribers {
+		if sub.ch == kch {
+			d.subscribers = append(d.subscribers[:i:i], d.subscribers[i+1:]...)
+			sub.stop()
+			return
+		}
+	}
+}
+
+// PauseMode defines what happens to key events while event delivery is
+// paused.
+type PauseMode int
+
+// Pause modes.
+const (
+	// PauseBuffer buffers key events and delivers them on resume.
+	PauseBuffer PauseMode = iota
+	// PauseDrop drops key events while paused.
+	PauseDrop
+)
+
+// PauseEvents pauses the delivery of key events to all subscribers, e.g. while
+// an application rebuilds its layout. Depending on the mode, key events get
+// buffered until ResumeEvents is called, or dropped.
+func (d *Device) PauseEvents(mode PauseMode) {
+	d.subscribersMutex.Lock()
+	defer d.subscribersMutex.Unlock()
+
+	d.paused = true
+	d.pauseMode = mode
+}
+
+// ResumeEvents resumes the delivery of key events, delivering all key events
+// that got buffered while paused. It never blocks on subscribers, so it can be
+// called from the goroutine reading the key events.
+func (d *Device) ResumeEvents() {
+	for {
+		// stay paused until all buffered key events got delivered, so new
+		// ones can't overtake them
+		d.subscribersMutex.Lock()
+		pending := d.pendingKeys
+		d.pendingKeys = nil
+		if len(pending) == 0 {
+			d.paused = false
+			d.subscribersMutex.Unlock()
+			return
+		}
+		subscribers := d.currentSubscribers()
+		d.subscribersMutex.Unlock()
+
+		deliver(subscribers, pending...)
+	}
+}
+
+// broadcast emits a key event to all subscribers, unless event delivery is
+// paused.
+func (d *Device) broadcast(key Key) {
+	d.subscribersMutex.Lock()
+	if d.paused {
+		if d.pauseMode == PauseBuffer {
+			d.pendingKeys = append(d.pendingKeys, key)
+		}
+		d.subscribersMutex.Unlock()
+		return
+	}
+	subscribers := d.currentSubscribers()
+	d.subscribersMutex.Unlock()
+
+	deliver(subscribers, key)
+}
+
+// currentSubscribers returns a copy of the list of subscribers. The caller
+// must hold the subscribers mutex.
+func (d *Device) currentSubscribers() []*subscriber {
+	return append([]*subscriber(nil), d.subscribers...)
+}
+
+// deliver queues key events for the given subscribers, without blocking.
+func deliver(subscribers []*subscriber, keys ...Key) {
+	for _, sub := range subscribers {
+		sub.push(keys...)
+	}
+}
 
-			// don't trigger a key event if the device is asleep, but wake it
-			if d.asleep {
-				_ = d.Wake()
+// closeSubscribers closes the channels of all subscribers.
+func (d *Device) closeSubscribers() {
+	d.subscribersMutex.Lock()
+	defer d.subscribersMutex.Unlock()
 
-				// reset state so no spurious key events get triggered
-				for i := d.keyStateOffset; i < len(keyBuffer); i++ {
-					keyBuffer[i] = 0
+	for _, sub := range d.subscribers {
+		sub.finish()
+	}
+	d.subscribers = nil
+	d.reading = false
+}
+
+// gracePeriod tracks the time after waking up during which key reports get
+// discarded.
+type gracePeriod struct {
+	until time.Time
+}
+
+// discard returns true if a key report read at now should be discarded. A
+// report waking the device up is always discarded and starts a new grace
+// period of the given duration.
+func (g *gracePeriod) discard(now time.Time, woke bool, d time.Duration) bool {
+	if woke {
+		g.until = now.Add(d)
+		return true
+	}
+	return now.Before(g.until)
+}
+
+// readKeys reads key events from the given device handle and emits them to
+// all subscribers, until reading from the device fails, e.g. because it got
+// closed. It keeps its own handle, as Close resets the device's.
+func (d *Device) readKeys(device *hid.Device) {
+	keyBuffer := make([]byte, d.keyStateOffset+len(d.keyState))
+	// keys held while input was disabled, whose releases must not be emitted
+	ignoreRelease := make([]bool, len(d.keyState))
+	var grace gracePeriod
+
+	// deliver feedback from a single goroutine, so it keeps the order of the
+	// key events
+	feedback := newSubscriber(0)
+	defer feedback.finish()
+	go d.runFeedback(feedback.ch)
+
+	for {
+		copy(d.keyState, keyBuffer[d.keyStateOffset:])
+
+		if _, err := device.Read(keyBuffer); err != nil {
+			d.closeSubscribers()
+			return
+		}
+
+		// ignore all input while the device is locked
+		if !d.InputEnabled() {
+			for i, state := range keyBuffer[d.keyStateOffset:] {
+				if state == 1 {
+					ignoreRelease[i] = true
 				}
-				continue
 			}
+			d.checkUnlockGesture(keyBuffer[d.keyStateOffset:])
+			continue
+		}
+		d.stopUnlockTimer()
+
+		// don't trigger a key event if the device is asleep, but wake it
+		woke := false
+		if d.asleep {
+			_ = d.Wake()
+			_ = d.stopScreensaver()
+			woke = true
+		}
 
-			d.sleepMutex.Lock()
-			d.lastActionTime = time.Now()
-			d.sleepMutex.Unlock()
+		// don't trigger a key event if the screensaver is running, but stop it
+		if d.ScreensaverActive() {
+			_ = d.stopScreensaver()
+			woke = true
+		}
 
+		// discard all reports for a grace period after waking up, so a quick
+		// double-tap can't leak a stale press. Reset state so no spurious key
+		// events get triggered.
+		if grace.discard(time.Now(), woke, d.wakeGrace) {
 			for i := d.keyStateOffset; i < len(keyBuffer); i++ {
-				keyIndex := uint8(i - d.keyStateOffset)
-				if keyBuffer[i] != d.keyState[keyIndex] {
-					kch <- Key{
-						Index:   d.translateKeyIndex(keyIndex, d.Columns),
-						Pressed: keyBuffer[i] == 1,
+				keyBuffer[i] = 0
+			}
+			continue
+		}
+
+		d.sleepMutex.Lock()
+		d.lastActionTime = time.Now()
+		d.resetSleepTimer()
+		d.sleepMutex.Unlock()
+
+		for i := d.keyStateOffset; i < len(keyBuffer); i++ {
+			keyIndex := uint8(i - d.keyStateOffset)
+			if keyBuffer[i] != d.keyState[keyIndex] {
+				if ignoreRelease[keyIndex] {
+					// the press happened while input was disabled, e.g. the
+					// unlock gesture
+					ignoreRelease[keyIndex] = false
+					if keyBuffer[i] == 0 {
+						continue
 					}
 				}
+
+				index := d.logicalKey(d.translateKeyIndex(keyIndex, d.Columns))
+				if !d.KeyEnabled(index) {
+					continue
+				}
+				key := Key{
+					Index:   index,
+					Pressed: keyBuffer[i] == 1,
+				}
+				if key.Pressed {
+					d.recordPress(index)
+					d.logEvent(EventPress, index)
+				} else {
+					d.logEvent(EventRelease, index)
+				}
+				if d.currentFeedback() != nil {
+					feedback.push(key)
+				}
+
+				key, ok := d.applyMiddleware(key)
+				if !ok {
+					continue
+				}
+
+				d.broadcast(key)
 			}
 		}
-	}()
+	}
+}
+
+// SetInputEnabled enables or disables input handling. While input is
+// disabled, the device keeps displaying images, but no key events get emitted
+// and key presses don't wake the device.
+func (d *Device) SetInputEnabled(enabled bool) {
+	d.inputMutex.Lock()
+	defer d.inputMutex.Unlock()
+
+	d.inputDisabled = !enabled
+}
+
+// InputEnabled returns true if the device currently handles input.
+func (d *Device) InputEnabled() bool {
+	d.inputMutex.Lock()
+	defer d.inputMutex.Unlock()
+
+	return !d.inputDisabled
+}
+
+// SetUnlockGesture configures a gesture that re-enables input while it is
+// disabled: both keys need to be held down for the given duration. A zero
+// duration removes the gesture. Releasing the keys after unlocking doesn't
+// emit key events.
+func (d *Device) SetUnlockGesture(key1, key2 uint8, hold time.Duration) error {
+	if key1 >= d.Keys || key2 >= d.Keys {
+		return fmt.Errorf("unlock gesture keys %d and %d out of range, device has %d keys", key1, key2, d.Keys)
+	}
+
+	d.inputMutex.Lock()
+	defer d.inputMutex.Unlock()
+
+	d.unlockKeys = []uint8{key1, key2}
+	d.unlockHold = hold
+	return nil
+}
+
+// checkUnlockGesture starts the unlock timer when all unlock keys are held
+// down in the given key state, and stops it as soon as one gets released.
+func (d *Device) checkUnlockGesture(state []byte) {
+	d.inputMutex.Lock()
+	defer d.inputMutex.Unlock()
+
+	if d.unlockHold == 0 {
+		return
+	}
+
+	held := true
+	for _, k := range d.unlockKeys {
+		if state[d.translateKeyIndex(d.physicalKey(k), d.Columns)] != 1 {
+			held = false
+		}
+	}
+
+	if !held {
+		d.stopUnlockTimerLocked()
+		return
+	}
+	if d.unlockTimer == nil {
+		var t *time.Timer
+		t = time.AfterFunc(d.unlockHold, func() {
+			d.inputMutex.Lock()
+			defer d.inputMutex.Unlock()
+
+			// the timer may have been stopped while this was waiting for
+			// the lock
+			if d.unlockTimer != t {
+				return
+			}
+			d.unlockTimer = nil
+			d.inputDisabled = false
+		})
+		d.unlockTimer = t
+	}
+}
+
+func (d *Device) stopUnlockTimer() {
+	d.inputMutex.Lock()
+	defer d.inputMutex.Unlock()
 
-	return kch, nil
+	d.stopUnlockTimerLocked()
+}
+
+// stopUnlockTimerLocked stops the unlock timer. The caller must hold the input
+// mutex.
+func (d *Device) stopUnlockTimerLocked() {
+	if d.unlockTimer == nil {
+		return
+	}
+
+	d.unlockTimer.Stop()
+	d.unlockTimer = nil
 }
 
 // Sleep puts the device asleep, waiting for a key event to wake it up.
 func (d *Device) Sleep() error {
+	slept, err := d.sleep()
+	if err != nil || !slept {
+		return err
+	}
+
+	d.notifySleepState(true)
+	return nil
+}
+
+// sleep puts the device asleep and returns true if it fell asleep. It returns
+// false if the device already was asleep, or if a brightness change while
+// fading out cancelled it.
+func (d *Device) sleep() (bool, error) {
 	d.sleepMutex.Lock()
 	defer d.sleepMutex.Unlock()
 
-	d.preSleepBrightness = d.brightness
+	if d.asleep {
+		return false, nil
+	}
+	d.brightnessMutex.Lock()
+	brightness := d.brightness
+	d.preSleepBrightness = brightness
+	d.brightnessMutex.Unlock()
 
-	if err := d.Fade(d.brightness, 0, d.fadeDuration); err != nil {
-		return err
+	cancelled, err := d.fade(brightness, 0, d.fadeDuration)
+	if err != nil {
+		return false, err
+	}
+	if cancelled {
+		// the brightness got changed while fading out, stay awake and try
+		// again after the next timeout
+		d.resetSleepTimer()
+		return false, nil
 	}
 
 	d.asleep = true
-	return d.SetBrightness(0)
+	return true, d.setBrightness(0)
 }
 
 // Wake wakes the device from sleep.
 func (d *Device) Wake() error {
+	woke, err := d.wake()
+	if err != nil || !woke {
+		return err
+	}
+
+	d.notifySleepState(false)
+	return nil
+}
+
+// wake wakes the device from sleep and returns true if it woke up, or false if
+// it wasn't asleep.
+func (d *Device) wake() (bool, error) {
 	d.sleepMutex.Lock()
 	defer d.sleepMutex.Unlock()
 
-	d.asleep = false
-	if err := d.Fade(0, d.preSleepBrightness, d.fadeDuration); err != nil {
-		return err
+	if !d.asleep {
+		return false, nil
 	}
+	d.asleep = false
+	d.brightnessMutex.Lock()
+	brightness := d.preSleepBrightness
+	d.brightnessMutex.Unlock()
+
+	// if the brightness got changed while fading in, the new value is kept
+	_, err := d.fadeTo(0, brightness, d.fadeDuration)
 
 	d.lastActionTime = time.Now()
-	return d.SetBrightness(d.preSleepBrightness)
+	d.resetSleepTimer()
+	return true, err
+}
+
+// OnSleepStateChange sets a callback, which gets called whenever the device
+// falls asleep or wakes up, e.g. to pause polling or animations while the
+// device is asleep.
+func (d *Device) OnSleepStateChange(fn func(asleep bool)) {
+	d.sleepStateCallback = fn
+}
+
+func (d *Device) notifySleepState(asleep bool) {
+	if asleep {
+		d.logEvent(EventSleep, 0)
+	} else {
+		d.logEvent(EventWake, 0)
+	}
+
+	d.hooksMutex.RLock()
+	hooks := d.sleepHooks
+	d.hooksMutex.RUnlock()
+	for _, e := range hooks {
+		e.fn(asleep)
+	}
+
+	if d.sleepStateCallback != nil {
+		d.sleepStateCallback(asleep)
+	}
+}
+
+// sleepHookEntry is a registered sleep state handler of a component.
+type sleepHookEntry struct {
+	id uint64
+	fn func(asleep bool)
+}
+
+// onSleepStateChange registers a component's sleep state handler, e.g. to
+// stop updating its buttons while the device is asleep. Unlike
+// OnSleepStateChange it doesn't replace the application's callback. The
+// returned function removes it again.
+func (d *Device) onSleepStateChange(fn func(asleep bool)) func() {
+	d.hooksMutex.Lock()
+	defer d.hooksMutex.Unlock()
+
+	d.hookID++
+	id := d.hookID
+	d.sleepHooks = append(d.sleepHooks, sleepHookEntry{id: id, fn: fn})
+
+	return func() {
+		d.hooksMutex.Lock()
+		defer d.hooksMutex.Unlock()
+
+		for i, e := range d.sleepHooks {
+			if e.id == id {
+				d.sleepHooks = append(d.sleepHooks[:i:i], d.sleepHooks[i+1:]...)
+				return
+			}
+		}
+	}
 }
 
 // Asleep returns true if the device is asleep.
@@ -329,12 +871,42 @@ func (d Device) Asleep() bool {
 }
 
 func (d *Device) cancelSleepTimer() {
-	if d.sleepCancel == nil {
+	d.sleepMutex.Lock()
+	defer d.sleepMutex.Unlock()
+
+	if d.sleepTimer == nil {
+		return
+	}
+
+	d.sleepTimer.Stop()
+	d.sleepTimer = nil
+}
+
+// resetSleepTimer restarts the sleep timeout, e.g. after a key event. The
+// caller must hold the sleep mutex.
+func (d *Device) resetSleepTimer() {
+	if d.sleepTimer != nil {
+		d.sleepTimer.Reset(d.sleepTimeout)
+	}
+}
+
+// sleepIfIdle puts the device asleep, unless a key event was received within
+// the sleep timeout.
+func (d *Device) sleepIfIdle() {
+	d.sleepMutex.Lock()
+	if d.asleep || d.sleepTimer == nil {
+		d.sleepMutex.Unlock()
+		return
+	}
+
+	if remaining := d.sleepTimeout - time.Since(d.lastActionTime); remaining > 0 {
+		d.sleepTimer.Reset(remaining)
+		d.sleepMutex.Unlock()
 		return
 	}
+	d.sleepMutex.Unlock()
 
-	d.sleepCancel()
-	d.sleepCancel = nil
+	_ = d.Sleep()
 }
 
 // SetSleepFadeDuration sets the duration of the fading animation when the
@@ -343,6 +915,13 @@ func (d *Device) SetSleepFadeDuration(t time.Duration) {
 	d.fadeDuration = t
 }
 
+// SetWakeGracePeriod sets how long key reports get discarded after the device
+// wakes up or the screensaver stops, so the key press waking it up doesn't
+// trigger any key events. It defaults to 250ms.
+func (d *Device) SetWakeGracePeriod(t time.Duration) {
+	d.wakeGrace = t
+}
+
 // SetSleepTimeout sets the time after which the device will sleep if no key
 // events are received.
 func (d *Device) SetSleepTimeout(t time.Duration) {
@@ -351,64 +930,193 @@ func (d *Device) SetSleepTimeout(t time.Duration) {
 		return
 	}
 
-	var ctx context.Context
-	ctx, d.sleepCancel = context.WithCancel(context.Background())
+	d.sleepMutex.Lock()
+	defer d.sleepMutex.Unlock()
+
+	d.sleepTimeout = t
+	d.sleepTimer = time.AfterFunc(t, d.sleepIfIdle)
+}
 
-	go func() {
-		for {
-			select {
-			case <-time.After(time.Second):
-				d.sleepMutex.RLock()
-				since := time.Since(d.lastActionTime)
-				d.sleepMutex.RUnlock()
+// Fade fades the brightness in or out, from start towards end percent over the
+// given duration. It returns before setting the end brightness itself. Starting
+// another fade or calling SetBrightness cancels a running fade.
+func (d *Device) Fade(start uint8, end uint8, duration time.Duration) error {
+	_, err := d.fade(start, end, duration)
+	return err
+}
 
-				if !d.asleep && since >= t {
-					_ = d.Sleep()
-				}
+// fade fades the brightness and returns true if the fade got cancelled. Like
+// Fade, it doesn't set the end brightness.
+func (d *Device) fade(start uint8, end uint8, duration time.Duration) (bool, error) {
+	return d.runFade(start, end, duration, false)
+}
 
-			case <-ctx.Done():
-				return
+// fadeTo fades the brightness and sets the end brightness, unless the fade got
+// cancelled. It returns true if the fade got cancelled.
+func (d *Device) fadeTo(start uint8, end uint8, duration time.Duration) (bool, error) {
+	return d.runFade(start, end, duration, true)
+}
+
+func (d *Device) runFade(start uint8, end uint8, duration time.Duration, setEnd bool) (bool, error) {
+	delay := d.frameDelay()
+
+	generation := d.cancelFades()
+
+	steps := fadeSteps(start, end, duration, delay)
+	if len(steps) > 0 {
+		ticker := time.NewTicker(delay)
+		defer ticker.Stop()
+
+		for _, brightness := range steps {
+			if cancelled, err := d.setFadeBrightness(generation, brightness); cancelled || err != nil {
+				return cancelled, err
 			}
+
+			<-ticker.C
 		}
-	}()
+	}
+
+	if setEnd {
+		return d.setFadeBrightness(generation, end)
+	}
+	return d.fadeCancelled(generation), nil
 }
 
-// Fade fades the brightness in or out.
-func (d *Device) Fade(start uint8, end uint8, duration time.Duration) error {
-	step := (float64(end) - float64(start)) / float64(duration/fadeDelay)
-	if step == math.Inf(1) || step == math.Inf(-1) {
+// frameDelay returns the delay between the frames of animations, which run at
+// a lower frame rate in low-power mode.
+func (d Device) frameDelay() time.Duration {
+	if d.lowPower {
+		return lowPowerFadeDelay
+	}
+	return fadeDelay
+}
+
+// fadeSteps returns the brightness of every frame of a fade from start towards
+// end percent over the given duration, with the given delay between frames.
+// The first frame has the start brightness, the end brightness is left to the
+// caller. There are no frames if start and end are equal or the duration is
+// shorter than a single frame.
+func fadeSteps(start, end uint8, duration, delay time.Duration) []uint8 {
+	if start > 100 {
+		start = 100
+	}
+	if end > 100 {
+		end = 100
+	}
+
+	steps := int(duration / delay)
+	if steps < 1 || start == end {
 		return nil
 	}
 
-	for current := float64(start); ; current += step {
-		if !((start < end && int8(current) < int8(end)) ||
-			(start > end && int8(current) > int8(end))) {
-			break
-		}
-		if err := d.SetBrightness(uint8(current)); err != nil {
-			return err
-		}
+	frames := make([]uint8, steps)
+	for i := range frames {
+		current := float64(start) + (float64(end)-float64(start))*float64(i)/float64(steps)
+		frames[i] = uint8(math.Round(current))
+	}
+	return frames
+}
 
-		time.Sleep(fadeDelay)
+// cancelFades cancels all running fades and returns the generation a new fade
+// can run under.
+func (d *Device) cancelFades() uint64 {
+	d.brightnessMutex.Lock()
+	defer d.brightnessMutex.Unlock()
+
+	d.fadeGeneration++
+	return d.fadeGeneration
+}
+
+// setFadeBrightness sets the brightness for the fade with the given
+// generation, unless it got cancelled. The check and the write happen under
+// the brightness mutex, so a cancelled fade can't overwrite a brightness set
+// in between. It returns true if the fade got cancelled.
+func (d *Device) setFadeBrightness(generation uint64, percent uint8) (bool, error) {
+	d.brightnessMutex.Lock()
+	defer d.brightnessMutex.Unlock()
+
+	if d.fadeGeneration != generation {
+		return true, nil
 	}
-	return nil
+	return false, d.setBrightnessLocked(percent)
+}
+
+// fadeCancelled returns true if the fade with the given generation got
+// cancelled.
+func (d *Device) fadeCancelled(generation uint64) bool {
+	d.brightnessMutex.Lock()
+	defer d.brightnessMutex.Unlock()
+
+	return d.fadeGeneration != generation
+}
+
+// SetLowPower enables or disables the low-power mode, e.g. when the host
+// switches to battery power. In low-power mode the brightness is capped and
+// fade animations run at a lower frame rate. Disabling it restores the
+// brightness that was requested.
+func (d *Device) SetLowPower(enabled bool) error {
+	d.lowPower = enabled
+
+	// the device can't report its brightness, so only re-apply one that was
+	// set before
+	d.brightnessMutex.Lock()
+	brightness, known := d.brightness, d.brightnessKnown
+	d.brightnessMutex.Unlock()
+	if !known {
+		return nil
+	}
+	return d.SetBrightness(brightness)
 }
 
-// SetBrightness sets the background lighting brightness from 0 to 100 percent.
+// LowPower returns true if the device is in low-power mode.
+func (d Device) LowPower() bool {
+	return d.lowPower
+}
+
+// SetBrightness sets the background lighting brightness from 0 to 100 percent,
+// cancelling any running fade.
 func (d *Device) SetBrightness(percent uint8) error {
+	d.brightnessMutex.Lock()
+	defer d.brightnessMutex.Unlock()
+
+	d.fadeGeneration++
+	return d.setBrightnessLocked(percent)
+}
+
+// Brightness returns the brightness in percent that was last set, including
+// by a running fade. The device can't report its brightness, so this is 0
+// until a brightness has been set.
+func (d Device) Brightness() uint8 {
+	return d.brightness
+}
+
+// setBrightness sets the brightness without cancelling fades.
+func (d *Device) setBrightness(percent uint8) error {
+	d.brightnessMutex.Lock()
+	defer d.brightnessMutex.Unlock()
+
+	return d.setBrightnessLocked(percent)
+}
+
+// setBrightnessLocked sets the brightness. The caller must hold the brightness
+// mutex.
+func (d *Device) setBrightnessLocked(percent uint8) error {
 	if percent > 100 {
 		percent = 100
 	}
 
 	d.brightness = percent
+	d.brightnessKnown = true
 	if d.asleep && percent > 0 {
 		// if the device is asleep, remember the brightness, but don't set it
-		d.sleepMutex.Lock()
 		d.preSleepBrightness = percent
-		d.sleepMutex.Unlock()
 		return nil
 	}
 
+	if d.lowPower && percent > lowPowerBrightness {
+		percent = lowPowerBrightness
+	}
+
 	report := make([]byte, len(d.setBrightnessCommand)+1)
 	copy(report, d.setBrightnessCommand)
 	report[len(report)-1] = percent
@@ -420,39 +1128,154 @@ func (d *Device) SetBrightness(percent uint8) error {
 // needs to be in the correct resolution for the device. The index starts with
 // 0 being the top-left button.
 func (d Device) SetImage(index uint8, img image.Image) error {
+	if err := d.checkImageSize(img); err != nil {
+		return err
+	}
+
+	if d.images != nil {
+		d.imagesMutex.Lock()
+		d.images[index] = img
+		d.imagesMutex.Unlock()
+	}
+
+	// keep the screensaver on the device, the image gets restored once it stops
+	if d.ScreensaverActive() {
+		return nil
+	}
+
+	return d.writeImage(index, d.keyImage(index, img))
+}
+
+// Redraw sends the images last set on all buttons to the device again, e.g.
+// after temporarily showing other content. Buttons without an image are set
+// to black.
+func (d Device) Redraw() error {
+	for i := uint8(0); i < d.Keys; i++ {
+		img := d.Image(i)
+		if img == nil {
+			black := image.NewRGBA(image.Rect(0, 0, int(d.Pixels), int(d.Pixels)))
+			draw.Draw(black, black.Bounds(), image.NewUniform(color.RGBA{0, 0, 0, 255}), image.Point{}, draw.Src)
+			img = black
+		}
+
+		if err := d.writeImage(i, d.keyImage(i, img)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkImageSize returns an error if the given image is not in the correct
+// resolution for the device.
+func (d Device) checkImageSize(img image.Image) error {
 	if img.Bounds().Dy() != int(d.Pixels) ||
 		img.Bounds().Dx() != int(d.Pixels) {
-		return fmt.Errorf("supplied image has wrong dimensions, expected %[1]dx%[1]d pixels", d.Pixels)
+		var transparency string
+		if hasAlpha(img) {
+			transparency = " (image has transparency)"
+		}
+		return fmt.Errorf("supplied image has wrong dimensions, expected %[1]dx%[1]d pixels, got %[2]dx%[3]d pixels%[4]s",
+			d.Pixels, img.Bounds().Dx(), img.Bounds().Dy(), transparency)
+	}
+
+	return nil
+}
+
+// Image returns the image that was last set on a button, or nil if no image
+// has been set since the device was opened.
+func (d Device) Image(index uint8) image.Image {
+	if d.images == nil {
+		return nil
+	}
+
+	d.imagesMutex.RLock()
+	defer d.imagesMutex.RUnlock()
+	return d.images[index]
+}
+
+// SetImageWithBackground sets the image of a button on the Stream Deck, like
+// SetImage, but composites transparent images onto the given background color
+// instead of the device's background color.
+func (d Device) SetImageWithBackground(index uint8, img image.Image, background color.Color) error {
+	if hasAlpha(img) {
+		img = flatten(img, background)
+	}
+	return d.SetImage(index, img)
+}
+
+// SetImageBackground sets the color transparent images get composited onto
+// before they are sent to the device. A nil color resets it to the default,
+// black.
+func (d *Device) SetImageBackground(c color.Color) {
+	d.background = c
+}
+
+// writeImage transfers an image to a button, without remembering it.
+func (d Device) writeImage(index uint8, img image.Image) error {
+	if hasAlpha(img) {
+		background := d.background
+		if background == nil {
+			background = color.Black
+		}
+		img = flatten(img, background)
+	}
+	if d.dithering != DitherNone {
+		img = dither(img, d.dithering)
 	}
 
 	imageBytes, err := d.toImageFormat(d.flipImage(img))
 	if err != nil {
 		return fmt.Errorf("cannot convert image data: %v", err)
 	}
-	imageData := imageData{
-		image:    imageBytes,
-		pageSize: d.imagePageSize - d.imagePageHeaderSize,
-	}
 
+	keyIndex := d.translateKeyIndex(d.physicalKey(index), d.Columns)
+	d.logf("writing image to key %d", index)
+
+	return d.writePaged(imageBytes, func(page int, lastPage bool, payloadLength int) []byte {
+		return d.imagePageHeader(page, keyIndex, payloadLength, lastPage)
+	})
+}
+
+// writePaged writes the payload to the device in pages of the device's image
+// page size, each page starting with the header returned for it.
+func (d Device) writePaged(payload []byte, header func(page int, lastPage bool, payloadLength int) []byte) error {
+	pages := Paginate(payload, d.imagePageSize-d.imagePageHeaderSize)
 	data := make([]byte, d.imagePageSize)
 
-	var page int
-	var lastPage bool
-	for !lastPage {
-		var payload []byte
-		payload, lastPage = imageData.Page(page)
-		header := d.imagePageHeader(page, d.translateKeyIndex(index, d.Columns), len(payload), lastPage)
+	if d.readOnly {
+		return ErrReadOnly
+	}
+	if !d.dryRun {
+		if d.device == nil {
+			return ErrDeviceClosed
+		}
+		d.writeMutex.Lock()
+		defer d.writeMutex.Unlock()
+	}
 
-		copy(data, header)
-		copy(data[len(header):], payload)
+	d.logf("writing %d bytes in %d pages", len(payload), len(pages))
 
-		_, err := d.device.Write(data)
-		if err != nil {
-			return fmt.Errorf("cannot write image page %d of %d (%d image bytes) %d bytes: %v",
-				page, imageData.PageCount(), imageData.Length(), len(data), err)
+	for page, pagePayload := range pages {
+		h := header(page, page == len(pages)-1, len(pagePayload))
+		if len(h) != d.imagePageHeaderSize {
+			return fmt.Errorf("page header of page %d is %d bytes long, expected %d bytes",
+				page, len(h), d.imagePageHeaderSize)
 		}
 
-		page++
+		copy(data, h)
+		copy(data[len(h):], pagePayload)
+
+		d.logf("writing page %d: header % x, %d payload bytes", page, h, len(pagePayload))
+		if !d.dryRun {
+			start := time.Now()
+			_, err := d.device.Write(data)
+			d.recordTransfer(len(data), time.Since(start), err)
+			if err != nil {
+				return fmt.Errorf("cannot write page %d of %d (%d payload bytes) %d bytes: %v",
+					page, len(pages), len(payload), len(data), err)
+			}
+		}
 	}
 
 	return nil
@@ -461,12 +1284,20 @@ func (d Device) SetImage(index uint8, img image.Image) error {
 // getFeatureReport from the device without worries about the correct payload
 // size.
 func (d Device) getFeatureReport(payload []byte) ([]byte, error) {
+	if d.device == nil {
+		return nil, ErrDeviceClosed
+	}
+	d.writeMutex.Lock()
+	defer d.writeMutex.Unlock()
+
 	b := make([]byte, d.featureReportSize)
 	copy(b, payload)
 	_, err := d.device.GetFeatureReport(b)
 	if err != nil {
 		return nil, err
 	}
+
+	d.logf("got feature report: % x", b)
 	return b, nil
 }
 
@@ -475,6 +1306,21 @@ func (d Device) getFeatureReport(payload []byte) ([]byte, error) {
 func (d Device) sendFeatureReport(payload []byte) error {
 	b := make([]byte, d.featureReportSize)
 	copy(b, payload)
+
+	d.logf("sending feature report: % x", b)
+	if d.readOnly {
+		return ErrReadOnly
+	}
+	if d.dryRun {
+		return nil
+	}
+
+	if d.device == nil {
+		return ErrDeviceClosed
+	}
+	d.writeMutex.Lock()
+	defer d.writeMutex.Unlock()
+
 	_, err := d.device.SendFeatureReport(b)
 	return err
 }
@@ -502,6 +1348,31 @@ func toRGBA(img image.Image) *image.RGBA {
 	return out
 }
 
+// hasAlpha returns true if the given image contains transparent pixels.
+func hasAlpha(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return !o.Opaque()
+	}
+
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if _, _, _, a := img.At(x, y).RGBA(); a != 0xffff {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// flatten returns the given image composited onto a solid background color.
+func flatten(img image.Image, background color.Color) image.Image {
+	flattened := image.NewRGBA(img.Bounds())
+	draw.Draw(flattened, flattened.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+	draw.Draw(flattened, flattened.Bounds(), img, img.Bounds().Min, draw.Over)
+	return flattened
+}
+
 // flipHorizontally returns the given image horizontally flipped.
 func flipHorizontally(img image.Image) image.Image {
 	flipped := image.NewRGBA(img.Bounds())
@@ -646,51 +1517,3 @@ func rev2ImagePageHeader(pageIndex int, keyIndex uint8, payloadLength int, lastP
 		byte(pageIndex), byte(pageIndex >> 8),
 	}
 }
-
-// imageData allows to access raw image data in a byte array through pages of a
-// given size.
-type imageData struct {
-	image    []byte
-	pageSize int
-}
-
-// Page returns the page with the given index and an indication if this is the
-// last page.
-func (d imageData) Page(pageIndex int) ([]byte, bool) {
-	offset := pageIndex * d.pageSize
-	if offset >= len(d.image) {
-		return []byte{}, true
-	}
-
-	length := d.pageLength(pageIndex)
-	if offset+length > len(d.image) {
-		length = len(d.image) - offset
-	}
-
-	return d.image[offset : offset+length], pageIndex == d.PageCount()-1
-}
-
-func (d imageData) pageLength(pageIndex int) int {
-	remaining := len(d.image) - (pageIndex * d.pageSize)
-	if remaining > d.pageSize {
-		return d.pageSize
-	}
-	if remaining > 0 {
-		return remaining
-	}
-	return 0
-}
-
-// PageCount returns the total number of pages.
-func (d imageData) PageCount() int {
-	count := len(d.image) / d.pageSize
-	if len(d.image)%d.pageSize != 0 {
-		return count + 1
-	}
-	return count
-}
-
-// Length of the raw image data in bytes.
-func (d imageData) Length() int {
-	return len(d.image)
-}
@@ -0,0 +1,243 @@
+package streamdeck
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFadeSteps(t *testing.T) {
+	tests := []struct {
+		name       string
+		start, end uint8
+		duration   time.Duration
+		frames     int
+	}{
+		{"fade in", 0, 100, time.Second, 30},
+		{"fade out", 100, 0, time.Second, 30},
+		{"equal start and end", 50, 50, time.Second, 0},
+		{"shorter than a frame", 0, 100, fadeDelay / 2, 0},
+		{"single frame", 0, 100, fadeDelay, 1},
+		{"zero duration", 0, 100, 0, 0},
+		{"clamped to 100", 0, 200, time.Second, 30},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			steps := fadeSteps(tt.start, tt.end, tt.duration, fadeDelay)
+			if len(steps) != tt.frames {
+				t.Fatalf("expected %d frames, got %d", tt.frames, len(steps))
+			}
+			if len(steps) == 0 {
+				return
+			}
+
+			if steps[0] != tt.start {
+				t.Errorf("expected first frame %d, got %d", tt.start, steps[0])
+			}
+			for i, b := range steps {
+				if b > 100 {
+					t.Errorf("frame %d has brightness %d", i, b)
+				}
+				if i == 0 {
+					continue
+				}
+
+				// frames must move monotonically towards the end
+				if (tt.end > tt.start && b < steps[i-1]) || (tt.end < tt.start && b > steps[i-1]) {
+					t.Errorf("frame %d moves away from the end: %d after %d", i, b, steps[i-1])
+				}
+			}
+		})
+	}
+}
+
+func TestFadeStepsReachesEnd(t *testing.T) {
+	steps := fadeSteps(0, 100, time.Second, fadeDelay)
+	if last := steps[len(steps)-1]; last < 95 || last >= 100 {
+		t.Errorf("expected the last frame just below 100, got %d", last)
+	}
+
+	steps = fadeSteps(100, 0, time.Second, fadeDelay)
+	if last := steps[len(steps)-1]; last > 5 || last == 0 {
+		t.Errorf("expected the last frame just above 0, got %d", last)
+	}
+}
+
+func TestGracePeriod(t *testing.T) {
+	now := time.Now()
+	grace := 250 * time.Millisecond
+
+	var g gracePeriod
+	if g.discard(now, false, grace) {
+		t.Fatal("discarded a report without waking up")
+	}
+
+	steps := []struct {
+		name    string
+		at      time.Duration
+		woke    bool
+		discard bool
+	}{
+		{"waking report", 0, true, true},
+		{"within grace period", 100 * time.Millisecond, false, true},
+		{"just before end", grace - time.Nanosecond, false, true},
+		{"at end", grace, false, false},
+		{"after end", time.Second, false, false},
+		{"waking again", 2 * time.Second, true, true},
+		{"within new grace period", 2*time.Second + 100*time.Millisecond, false, true},
+	}
+	for _, st := range steps {
+		if got := g.discard(now.Add(st.at), st.woke, grace); got != st.discard {
+			t.Errorf("%s: expected discard %v, got %v", st.name, st.discard, got)
+		}
+	}
+}
+
+func TestZeroGracePeriod(t *testing.T) {
+	now := time.Now()
+
+	var g gracePeriod
+	if !g.discard(now, true, 0) {
+		t.Error("waking report leaked with a zero grace period")
+	}
+	if g.discard(now, false, 0) {
+		t.Error("discarded a report after a zero grace period")
+	}
+}
+
+// benchmarkSizes are the key sizes of the supported devices, in pixels.
+var benchmarkSizes = []int{72, 80, 96}
+
+// benchmarkImage returns a gradient image of the given size, so encoders
+// can't take shortcuts for uniform areas.
+func benchmarkImage(size int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			img.SetRGBA(x, y, color.RGBA{uint8(x * 255 / size), uint8(y * 255 / size), uint8((x + y) * 127 / size), 0xff})
+		}
+	}
+	return img
+}
+
+func benchmarkImageFunc(b *testing.B, fn func(image.Image)) {
+	for _, size := range benchmarkSizes {
+		img := benchmarkImage(size)
+		b.Run(fmt.Sprintf("%dpx", size), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				fn(img)
+			}
+		})
+	}
+}
+
+func BenchmarkToBMP(b *testing.B) {
+	benchmarkImageFunc(b, func(img image.Image) {
+		if _, err := toBMP(img); err != nil {
+			b.Fatal(err)
+		}
+	})
+}
+
+func BenchmarkToJPEG(b *testing.B) {
+	benchmarkImageFunc(b, func(img image.Image) {
+		if _, err := toJPEG(img); err != nil {
+			b.Fatal(err)
+		}
+	})
+}
+
+func BenchmarkFlipHorizontally(b *testing.B) {
+	benchmarkImageFunc(b, func(img image.Image) {
+		flipHorizontally(img)
+	})
+}
+
+func BenchmarkFlipHorizontallyAndVertically(b *testing.B) {
+	benchmarkImageFunc(b, func(img image.Image) {
+		flipHorizontallyAndVertically(img)
+	})
+}
+
+func BenchmarkRotateCounterclockwise(b *testing.B) {
+	benchmarkImageFunc(b, func(img image.Image) {
+		rotateCounterclockwise(img)
+	})
+}
+
+// benchmarkDevices are dry-run devices with the image pipelines of the
+// supported models, so SetImage runs without any hardware attached.
+var benchmarkDevices = []struct {
+	name string
+	d    Device
+}{
+	{"rev1", Device{
+		Columns: 5, Rows: 3, Keys: 15, Pixels: 72,
+		translateKeyIndex:   translateRightToLeft,
+		imagePageSize:       7819,
+		imagePageHeaderSize: 16,
+		imagePageHeader:     rev1ImagePageHeader,
+		flipImage:           flipHorizontally,
+		toImageFormat:       toBMP,
+	}},
+	{"mini", Device{
+		Columns: 3, Rows: 2, Keys: 6, Pixels: 80,
+		translateKeyIndex:   identity,
+		imagePageSize:       1024,
+		imagePageHeaderSize: 16,
+		imagePageHeader:     miniImagePageHeader,
+		flipImage:           rotateCounterclockwise,
+		toImageFormat:       toBMP,
+	}},
+	{"rev2", Device{
+		Columns: 5, Rows: 3, Keys: 15, Pixels: 72,
+		translateKeyIndex:   identity,
+		imagePageSize:       1024,
+		imagePageHeaderSize: 8,
+		imagePageHeader:     rev2ImagePageHeader,
+		flipImage:           flipHorizontallyAndVertically,
+		toImageFormat:       toJPEG,
+	}},
+}
+
+// BenchmarkSetImage measures the whole image pipeline of each model, from
+// flattening and dithering to encoding and paging.
+func BenchmarkSetImage(b *testing.B) {
+	for _, bd := range benchmarkDevices {
+		d := bd.d
+		d.dryRun = true
+		d.hooksMutex = &sync.RWMutex{}
+
+		opaque := benchmarkImage(int(d.Pixels))
+		transparent := image.NewNRGBA(opaque.Bounds())
+		for y := 0; y < int(d.Pixels); y++ {
+			for x := 0; x < int(d.Pixels); x++ {
+				r, g, bl, _ := opaque.At(x, y).RGBA()
+				transparent.SetNRGBA(x, y, color.NRGBA{uint8(r >> 8), uint8(g >> 8), uint8(bl >> 8), uint8(x * 255 / int(d.Pixels))})
+			}
+		}
+
+		b.Run(bd.name, func(b *testing.B) {
+			benchmarkSetImage(b, d, opaque)
+		})
+		b.Run(bd.name+"/flattened-dithered", func(b *testing.B) {
+			d := d
+			d.dithering = DitherFloydSteinberg
+			benchmarkSetImage(b, d, transparent)
+		})
+	}
+}
+
+func benchmarkSetImage(b *testing.B, d Device, img image.Image) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := d.SetImage(uint8(i)%d.Keys, img); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package streamdeck
+
+import "sync"
+
+// subscriber queues key events for a subscription and delivers them from its
+// own goroutine, so a slow subscriber doesn't hold up the others.
+type subscriber struct {
+	ch chan Key
+
+	mu       sync.Mutex
+	queue    []Key
+	finished bool
+	wake     chan struct{}
+	done     chan struct{}
+}
+
+func newSubscriber(buffer int) *subscriber {
+	s := &subscriber{
+		ch:   make(chan Key, buffer),
+		wake: make(chan struct{}, 1),
+		done: make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+// push queues key events for delivery. It never blocks.
+func (s *subscriber) push(keys ...Key) {
+	s.mu.Lock()
+	s.queue = append(s.queue, keys...)
+	s.mu.Unlock()
+
+	s.notify()
+}
+
+// finish closes the channel once all queued key events got delivered.
+func (s *subscriber) finish() {
+	s.mu.Lock()
+	s.finished = true
+	s.mu.Unlock()
+
+	s.notify()
+}
+
+// stop discards all queued key events and closes the channel.
+func (s *subscriber) stop() {
+	close(s.done)
+}
+
+func (s *subscriber) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run delivers queued key events until the subscriber gets stopped or
+// finished.
+func (s *subscriber) run() {
+	defer close(s.ch)
+
+	for {
+		s.mu.Lock()
+		if len(s.queue) == 0 {
+			finished := s.finished
+			s.mu.Unlock()
+
+			if finished {
+				return
+			}
+			select {
+			case <-s.wake:
+			case <-s.done:
+				return
+			}
+			continue
+		}
+		key := s.queue[0]
+		s.queue = s.queue[1:]
+		s.mu.Unlock()
+
+		select {
+		case s.ch <- key:
+		case <-s.done:
+			return
+		}
+	}
+}
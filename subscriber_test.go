@@ -0,0 +1,103 @@
+package streamdeck
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func testDevice(subs ...*subscriber) *Device {
+	return &Device{
+		subscribers:      subs,
+		subscribersMutex: &sync.Mutex{},
+	}
+}
+
+func TestSlowSubscriberDoesNotBlockOthers(t *testing.T) {
+	slow := newSubscriber(0)
+	fast := newSubscriber(0)
+	d := testDevice(slow, fast)
+
+	for i := uint8(0); i < 10; i++ {
+		d.broadcast(Key{Index: i, Pressed: true})
+	}
+
+	for i := uint8(0); i < 10; i++ {
+		select {
+		case k := <-fast.ch:
+			if k.Index != i {
+				t.Fatalf("expected key %d, got %d", i, k.Index)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("key %d not delivered", i)
+		}
+	}
+}
+
+func TestUnsubscribeWithoutReading(t *testing.T) {
+	sub := newSubscriber(0)
+	d := testDevice(sub)
+	d.broadcast(Key{Index: 1})
+
+	done := make(chan struct{})
+	go func() {
+		d.Unsubscribe(sub.ch)
+		d.broadcast(Key{Index: 2})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("unsubscribing deadlocked")
+	}
+
+	// the channel gets closed, possibly after a pending key event
+	for range sub.ch {
+	}
+}
+
+func TestCloseSubscribersDeliversPending(t *testing.T) {
+	sub := newSubscriber(0)
+	d := testDevice(sub)
+	d.broadcast(Key{Index: 1})
+	d.broadcast(Key{Index: 2})
+	d.closeSubscribers()
+
+	var got []uint8
+	for k := range sub.ch {
+		got = append(got, k.Index)
+	}
+	if len(got) != 2 || got[0] != 1 || got[1] != 2 {
+		t.Fatalf("expected keys [1 2], got %v", got)
+	}
+}
+
+func TestResumeEventsFromReader(t *testing.T) {
+	sub := newSubscriber(0)
+	d := testDevice(sub)
+
+	d.PauseEvents(PauseBuffer)
+	d.broadcast(Key{Index: 1})
+	d.broadcast(Key{Index: 2})
+
+	// resuming without reading the channel must not block
+	done := make(chan struct{})
+	go func() {
+		d.ResumeEvents()
+		d.broadcast(Key{Index: 3})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("resuming deadlocked")
+	}
+
+	for i := uint8(1); i <= 3; i++ {
+		if k := <-sub.ch; k.Index != i {
+			t.Fatalf("expected key %d, got %d", i, k.Index)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package streamdeck
+
+import "strings"
+
+// SupportLevel describes how well a device is supported by this package.
+type SupportLevel int
+
+// Support levels.
+const (
+	// SupportUnknown means the device is not a known Stream Deck model.
+	// Devices skips such devices and reports them to UnknownDeviceHandler
+	// instead.
+	SupportUnknown SupportLevel = iota
+	// SupportProtocolCompatible means the model is known and speaks a
+	// supported protocol, but the combination of model and firmware has not
+	// been tested.
+	SupportProtocolCompatible
+	// SupportTested means the combination of model and firmware has been
+	// tested.
+	SupportTested
+)
+
+// String returns a human readable name of the support level.
+func (s SupportLevel) String() string {
+	switch s {
+	case SupportTested:
+		return "tested"
+	case SupportProtocolCompatible:
+		return "protocol-compatible"
+	default:
+		return "unknown"
+	}
+}
+
+// TestedDevice is an entry of the support matrix.
+type TestedDevice struct {
+	Model Model
+	// Firmware is the prefix of the tested firmware versions. An empty
+	// Firmware matches all versions.
+	Firmware string
+}
+
+// TestedDevices is the support matrix of tested models and firmware versions.
+// It only lists combinations verified on real hardware, all other known
+// models are protocol-compatible. No combination has been verified yet;
+// entries get added as they are, and applications may add the combinations
+// they tested themselves.
+var TestedDevices []TestedDevice
+
+// UnknownDeviceHandler gets called by Devices for every Elgato device with an
+// unknown product ID, which Devices skips, e.g. to ask users to report the
+// device for triage.
+var UnknownDeviceHandler func(productID uint16, serial string)
+
+// SupportLevel returns how well the device is supported, so applications can
+// warn users about untested models or firmware. If the support matrix
+// restricts the model to certain firmware versions, the device must be open
+// to read its firmware version.
+func (d Device) SupportLevel() SupportLevel {
+	model := d.Model()
+	if model == ModelUnknown {
+		return SupportUnknown
+	}
+
+	var firmware string
+	for _, t := range TestedDevices {
+		if t.Model != model {
+			continue
+		}
+		if t.Firmware == "" {
+			return SupportTested
+		}
+
+		if firmware == "" {
+			var err error
+			if firmware, err = d.FirmwareVersion(); err != nil {
+				return SupportProtocolCompatible
+			}
+		}
+		if strings.HasPrefix(firmware, t.Firmware) {
+			return SupportTested
+		}
+	}
+
+	return SupportProtocolCompatible
+}
@@ -0,0 +1,170 @@
+package streamdeck
+
+import (
+	"image/color"
+	"math"
+	"time"
+
+	"golang.org/x/image/font/opentype"
+)
+
+// Theme defines the look of the content the library generates, like labels,
+// highlights and progress indicators.
+type Theme struct {
+	Background color.Color
+	Foreground color.Color
+	Accent     color.Color
+	// Font used for labels. A nil font uses the Go regular font, or the Go
+	// bold font if Bold is set.
+	Font *opentype.Font
+	Bold bool
+	// MinFontSize is the smallest font size in points labels get shrunk to
+	// when fitting their text. Longer text gets cut off with an ellipsis.
+	MinFontSize float64
+	// MaxFontSize is the largest font size in points labels get drawn with.
+	// Zero picks a size relative to the key size.
+	MaxFontSize float64
+	// CornerRadius rounds the corners of generated buttons, in pixels.
+	CornerRadius int
+	// TimeLayout formats the time shown by clocks, as understood by
+	// time.Format, e.g. to follow the conventions of the user's locale. An
+	// empty layout uses "15:04".
+	TimeLayout string
+	// FormatDuration formats the time shown by countdowns and stopwatches. A
+	// nil function uses mm:ss, or h:mm:ss from an hour on.
+	FormatDuration func(time.Duration) string
+}
+
+// Built-in themes.
+var (
+	DarkTheme = Theme{
+		Background: color.Black,
+		Foreground: color.White,
+		Accent:     color.RGBA{0x00, 0xa0, 0xff, 0xff},
+	}
+	LightTheme = Theme{
+		Background: color.White,
+		Foreground: color.Black,
+		Accent:     color.RGBA{0x00, 0x60, 0xd0, 0xff},
+	}
+	HighContrastTheme = Theme{
+		Background: color.Black,
+		Foreground: color.White,
+		Accent:     color.RGBA{0xff, 0xff, 0x00, 0xff},
+		Bold:       true,
+	}
+)
+
+// minAccessibleFontSize is the smallest label font size in accessibility
+// mode, relative to the key size.
+const minAccessibleFontSize = 0.3
+
+// SetTheme sets the theme of the generated content and re-renders all
+// components using it, e.g. to switch between light and dark mode at runtime.
+func (d *Device) SetTheme(t Theme) error {
+	d.hooksMutex.Lock()
+	d.theme = &t
+	d.hooksMutex.Unlock()
+
+	return d.rerender()
+}
+
+// formatTime formats the given time with the theme's time layout.
+func (t Theme) formatTime(now time.Time) string {
+	if t.TimeLayout == "" {
+		return now.Format("15:04")
+	}
+	return now.Format(t.TimeLayout)
+}
+
+// formatDuration formats the given duration with the theme's duration format.
+func (t Theme) formatDuration(d time.Duration) string {
+	if t.FormatDuration == nil {
+		return formatSeconds(int(d / time.Second))
+	}
+	return t.FormatDuration(d)
+}
+
+// rerender re-renders all components using the theme.
+func (d *Device) rerender() error {
+	d.hooksMutex.RLock()
+	themed := d.themed
+	d.hooksMutex.RUnlock()
+
+	for _, e := range themed {
+		if err := e.render(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetAccessibility toggles the accessibility mode, which renders all
+// generated content with the high-contrast palette, labels in the Go bold font
+// and a large minimum font size, regardless of the current theme.
+func (d *Device) SetAccessibility(enabled bool) error {
+	d.hooksMutex.Lock()
+	d.accessibility = enabled
+	d.hooksMutex.Unlock()
+
+	return d.rerender()
+}
+
+// Accessibility returns true if the accessibility mode is enabled.
+func (d *Device) Accessibility() bool {
+	d.hooksMutex.RLock()
+	defer d.hooksMutex.RUnlock()
+
+	return d.accessibility
+}
+
+// Theme returns the current theme. It defaults to DarkTheme.
+func (d *Device) Theme() Theme {
+	d.hooksMutex.RLock()
+	t := DarkTheme
+	if d.theme != nil {
+		t = *d.theme
+	}
+	accessibility := d.accessibility
+	d.hooksMutex.RUnlock()
+
+	if accessibility {
+		t.Background = HighContrastTheme.Background
+		t.Foreground = HighContrastTheme.Foreground
+		t.Accent = HighContrastTheme.Accent
+		t.Font = nil
+		t.Bold = true
+		t.MinFontSize = math.Max(t.MinFontSize, float64(d.Pixels)*minAccessibleFontSize)
+	}
+	return t
+}
+
+// themedEntry is a registered render function of a component.
+type themedEntry struct {
+	id     uint64
+	render func() error
+}
+
+// onThemeChange registers a component's render function, to re-render it when
+// the theme changes. The returned function removes it again.
+func (d *Device) onThemeChange(render func() error) func() {
+	d.hooksMutex.Lock()
+	defer d.hooksMutex.Unlock()
+
+	d.hookID++
+	id := d.hookID
+	d.themed = append(d.themed, themedEntry{id: id, render: render})
+
+	return func() {
+		d.hooksMutex.Lock()
+		defer d.hooksMutex.Unlock()
+
+		for i, e := range d.themed {
+			if e.id == id {
+				d.themed = append(d.themed[:i:i], d.themed[i+1:]...)
+				return
+			}
+		}
+	}
+}
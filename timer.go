@@ -0,0 +1,254 @@
+package streamdeck
+
+import (
+	"sync"
+	"time"
+)
+
+// timer is the state and control logic shared by Stopwatch and Countdown. It
+// measures the elapsed time while running, updates the display on every
+// second counted while the device is awake, and starts, pauses and resets on
+// presses of its control buttons.
+type timer struct {
+	d          *Device
+	keys       []uint8
+	startPause uint8
+	reset      uint8
+	// renderState shows the timer's state, called with mu held.
+	renderState func() error
+	// onLimit gets called once the elapsed time reached the limit.
+	onLimit func(asleep bool)
+	remove  []func()
+
+	mu      sync.Mutex
+	limited bool
+	limit   time.Duration
+	elapsed time.Duration
+	started time.Time
+	running bool
+	asleep  bool
+	stop    chan struct{}
+}
+
+// register binds the timer to its buttons and to theme and sleep state
+// changes.
+func (t *timer) register() {
+	// register the sleep hook before reading the sleep state, so a sleep in
+	// between can't be missed
+	t.remove = []func(){
+		t.d.Use(t.handleKey),
+		t.d.onThemeChange(t.render),
+		t.d.onSleepStateChange(t.sleepStateChanged),
+	}
+
+	t.mu.Lock()
+	t.asleep = t.d.Asleep()
+	t.mu.Unlock()
+}
+
+// Close stops the timer and removes it, so key events of its buttons get
+// emitted again. The buttons keep their images until they get replaced.
+func (t *timer) Close() {
+	for _, remove := range t.remove {
+		remove()
+	}
+
+	t.mu.Lock()
+	t.pauseLocked()
+	t.mu.Unlock()
+}
+
+// Running returns true if the timer is running.
+func (t *timer) Running() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.running
+}
+
+// Start starts or resumes the timer.
+func (t *timer) Start() error {
+	t.mu.Lock()
+	if t.running || t.limited && t.elapsed >= t.limit {
+		t.mu.Unlock()
+		return nil
+	}
+	t.running = true
+	t.started = time.Now()
+	t.updateTickerLocked()
+	t.mu.Unlock()
+
+	return t.render()
+}
+
+// Pause pauses the timer.
+func (t *timer) Pause() error {
+	t.mu.Lock()
+	t.pauseLocked()
+	t.mu.Unlock()
+
+	return t.render()
+}
+
+// Reset stops the timer and resets the elapsed time.
+func (t *timer) Reset() error {
+	t.mu.Lock()
+	t.pauseLocked()
+	t.elapsed = 0
+	t.mu.Unlock()
+
+	return t.render()
+}
+
+func (t *timer) pauseLocked() {
+	if !t.running {
+		return
+	}
+
+	t.elapsed = t.elapsedLocked()
+	t.running = false
+	t.updateTickerLocked()
+}
+
+func (t *timer) elapsedLocked() time.Duration {
+	if !t.running {
+		return t.elapsed
+	}
+	return t.elapsed + time.Since(t.started)
+}
+
+// updateTickerLocked updates the display on every second counted towards the
+// limit, while the timer is running and the device is awake. While the device
+// is asleep, a limited timer only wakes up to reach its limit.
+func (t *timer) updateTickerLocked() {
+	if t.stop != nil {
+		close(t.stop)
+		t.stop = nil
+	}
+	if !t.running || t.asleep && !t.limited {
+		return
+	}
+
+	stop := make(chan struct{})
+	t.stop = stop
+	origin := t.started.Add(t.limit - t.elapsed)
+	if !t.asleep {
+		go tickSeconds(origin, stop, func() {
+			t.tick(stop)
+		})
+		return
+	}
+
+	go func() {
+		end := time.NewTimer(time.Until(origin))
+		defer end.Stop()
+
+		select {
+		case <-end.C:
+			t.tick(stop)
+		case <-stop:
+		}
+	}()
+}
+
+// tick renders the timer, or stops it once it reached its limit. It does
+// nothing once the ticker got stopped in the meantime.
+func (t *timer) tick(stop chan struct{}) {
+	t.mu.Lock()
+	if t.stop != stop {
+		t.mu.Unlock()
+		return
+	}
+	if !t.limited || t.elapsedLocked() < t.limit {
+		if !t.asleep {
+			_ = t.renderState()
+		}
+		t.mu.Unlock()
+		return
+	}
+
+	t.elapsed = t.limit
+	t.running = false
+	t.updateTickerLocked()
+	asleep := t.asleep
+	t.mu.Unlock()
+
+	t.onLimit(asleep)
+}
+
+// sleepStateChanged stops updating the timer while the device is asleep, and
+// catches up on waking up.
+func (t *timer) sleepStateChanged(asleep bool) {
+	t.mu.Lock()
+	t.asleep = asleep
+	t.updateTickerLocked()
+	t.mu.Unlock()
+
+	if !asleep {
+		_ = t.render()
+	}
+}
+
+// render shows the timer's state across its buttons and labels its control
+// buttons.
+func (t *timer) render() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.renderState()
+}
+
+// handleKey is the middleware controlling the timer on button presses.
+func (t *timer) handleKey(k Key) (Key, bool) {
+	switch k.Index {
+	case t.startPause:
+		if k.Pressed {
+			if t.Running() {
+				_ = t.Pause()
+			} else {
+				_ = t.Start()
+			}
+		}
+		return k, false
+
+	case t.reset:
+		if k.Pressed {
+			_ = t.Reset()
+		}
+		return k, false
+	}
+
+	for _, key := range t.keys {
+		if key == k.Index {
+			return k, false
+		}
+	}
+	return k, true
+}
+
+// setControls labels the start/pause and reset buttons of a timer.
+func (d *Device) setControls(startPause, reset uint8, running bool, theme Theme) error {
+	label := "Start"
+	if running {
+		label = "Pause"
+	}
+	controls := []struct {
+		key   uint8
+		label string
+	}{
+		{startPause, label},
+		{reset, "Reset"},
+	}
+
+	size := int(d.Pixels)
+	for _, ctrl := range controls {
+		img, err := renderLabel(size, ctrl.label, theme)
+		if err != nil {
+			return err
+		}
+		if err := d.SetImage(ctrl.key, img); err != nil {
+			return err
+		}
+	}
+	return nil
+}
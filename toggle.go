@@ -0,0 +1,144 @@
+package streamdeck
+
+import (
+	"image"
+	"sync"
+	"time"
+)
+
+// defaultDebounce is the time during which further presses of a toggle
+// button get ignored.
+const defaultDebounce = 50 * time.Millisecond
+
+// Toggle binds a button to a boolean state, showing one image while the
+// state is on and another one while it is off. Pressing the button toggles
+// the state.
+type Toggle struct {
+	d        *Device
+	index    uint8
+	on, off  image.Image
+	onChange func(bool)
+	remove   func()
+
+	mu        sync.Mutex
+	state     bool
+	source    func() bool
+	debounce  time.Duration
+	lastPress time.Time
+}
+
+// NewToggle creates a toggle on the given button, which starts in the off
+// state. onChange gets called with the new state whenever the button toggles
+// it. Key events of the button are consumed and not emitted until the toggle
+// gets closed.
+func (d *Device) NewToggle(index uint8, on, off image.Image, onChange func(bool)) (*Toggle, error) {
+	t := &Toggle{
+		d:        d,
+		index:    index,
+		on:       on,
+		off:      off,
+		onChange: onChange,
+		debounce: defaultDebounce,
+	}
+	if err := d.SetImage(index, off); err != nil {
+		return nil, err
+	}
+
+	t.remove = d.Use(t.handleKey)
+	return t, nil
+}
+
+// Close removes the toggle, so key events of its button get emitted again.
+// The button keeps its image until it gets replaced.
+func (t *Toggle) Close() {
+	t.remove()
+}
+
+// State returns the current state of the toggle.
+func (t *Toggle) State() bool {
+	t.mu.Lock()
+	source := t.source
+	state := t.state
+	t.mu.Unlock()
+
+	// the source may call back into the toggle, so don't hold the lock
+	if source != nil {
+		return source()
+	}
+	return state
+}
+
+// Set sets the state of the toggle and updates its image, without calling
+// onChange.
+func (t *Toggle) Set(state bool) error {
+	t.mu.Lock()
+	t.state = state
+	t.mu.Unlock()
+
+	if state {
+		return t.d.SetImage(t.index, t.on)
+	}
+	return t.d.SetImage(t.index, t.off)
+}
+
+// SetStateSource sets a function reporting the actual state, e.g. of an
+// external system the toggle controls. After each press the toggle shows the
+// state reported by the source, instead of assuming the change succeeded.
+// Changes of the external state don't update the button on their own, call
+// Refresh for that. The source gets called without holding the toggle's lock,
+// so it may call the toggle's methods.
+func (t *Toggle) SetStateSource(source func() bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.source = source
+}
+
+// Refresh updates the toggle's image to the state reported by its state
+// source, e.g. after the state of the external system changed. Without a
+// state source it redraws the current state.
+func (t *Toggle) Refresh() error {
+	return t.Set(t.State())
+}
+
+// SetDebounce sets the time during which further presses get ignored after
+// the button toggled the state.
+func (t *Toggle) SetDebounce(debounce time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.debounce = debounce
+}
+
+// handleKey is the middleware toggling the state on button presses.
+func (t *Toggle) handleKey(k Key) (Key, bool) {
+	if k.Index != t.index {
+		return k, true
+	}
+	if !k.Pressed {
+		return k, false
+	}
+
+	t.mu.Lock()
+	if time.Since(t.lastPress) < t.debounce {
+		t.mu.Unlock()
+		return k, false
+	}
+	t.lastPress = time.Now()
+	t.mu.Unlock()
+
+	state := !t.State()
+	if t.onChange != nil {
+		t.onChange(state)
+	}
+
+	t.mu.Lock()
+	source := t.source
+	t.mu.Unlock()
+	if source != nil {
+		state = source()
+	}
+
+	_ = t.Set(state)
+	return k, false
+}
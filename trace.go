@@ -0,0 +1,27 @@
+package streamdeck
+
+// Logger traces the communication with a device. It is implemented by
+// *log.Logger.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// SetLogger sets a logger that traces all feature reports and image pages
+// exchanged with the device. A nil logger disables tracing.
+func (d *Device) SetLogger(l Logger) {
+	d.logger = l
+}
+
+// SetDryRun enables or disables the dry-run mode. In dry-run mode images and
+// feature reports get prepared and traced, but never sent to the device, so
+// the device doesn't need to be opened.
+func (d *Device) SetDryRun(dryRun bool) {
+	d.dryRun = dryRun
+}
+
+// logf traces a message, if a logger is set.
+func (d Device) logf(format string, v ...interface{}) {
+	if d.logger != nil {
+		d.logger.Printf(format, v...)
+	}
+}
@@ -0,0 +1,65 @@
+package streamdeck
+
+import (
+	"sync"
+	"time"
+)
+
+// TransferStats holds statistics about the data written to the device.
+type TransferStats struct {
+	// Bytes is the total number of bytes written, including page headers
+	// and padding.
+	Bytes uint64
+	// Pages is the number of pages written.
+	Pages uint64
+	// Duration is the total time spent writing.
+	Duration time.Duration
+	// Errors is the number of failed writes.
+	Errors uint64
+}
+
+// Throughput returns the effective write throughput in bytes per second.
+func (s TransferStats) Throughput() float64 {
+	if s.Duration == 0 {
+		return 0
+	}
+	return float64(s.Bytes) / s.Duration.Seconds()
+}
+
+// transferStats collects transfer statistics, shared by all copies of a
+// device.
+type transferStats struct {
+	sync.Mutex
+	TransferStats
+}
+
+// TransferStats returns statistics about the data written to the device since
+// it was opened.
+func (d Device) TransferStats() TransferStats {
+	if d.transfers == nil {
+		return TransferStats{}
+	}
+
+	d.transfers.Lock()
+	defer d.transfers.Unlock()
+	return d.transfers.TransferStats
+}
+
+// recordTransfer adds a write of the given number of bytes to the transfer
+// statistics.
+func (d Device) recordTransfer(bytes int, duration time.Duration, err error) {
+	if d.transfers == nil {
+		return
+	}
+
+	d.transfers.Lock()
+	defer d.transfers.Unlock()
+
+	d.transfers.Duration += duration
+	if err != nil {
+		d.transfers.Errors++
+		return
+	}
+	d.transfers.Bytes += uint64(bytes)
+	d.transfers.Pages++
+}
@@ -0,0 +1,103 @@
+package streamdeck
+
+import (
+	"image"
+	"image/color"
+	"time"
+
+	"golang.org/x/image/draw"
+)
+
+// Transition defines the animation used when changing the image of a button.
+type Transition int
+
+// Transitions.
+const (
+	// TransitionCrossfade blends the current image into the new one.
+	TransitionCrossfade Transition = iota
+	// TransitionSlideLeft slides the new image in from the right.
+	TransitionSlideLeft
+	// TransitionSlideRight slides the new image in from the left.
+	TransitionSlideRight
+	// TransitionSlideUp slides the new image in from the bottom.
+	TransitionSlideUp
+	// TransitionSlideDown slides the new image in from the top.
+	TransitionSlideDown
+)
+
+// TransitionImage sets the image of a button on the Stream Deck, animating the
+// change from the button's current image with the given transition. This call
+// blocks until the animation has finished.
+func (d Device) TransitionImage(index uint8, img image.Image, t Transition, duration time.Duration) error {
+	if err := d.checkImageSize(img); err != nil {
+		return err
+	}
+
+	from := d.Image(index)
+	if from == nil {
+		black := image.NewRGBA(image.Rect(0, 0, int(d.Pixels), int(d.Pixels)))
+		draw.Draw(black, black.Bounds(), image.NewUniform(color.Black), image.Point{}, draw.Src)
+		from = black
+	}
+
+	delay := d.frameDelay()
+	frames := int(duration / delay)
+	for f := 1; f < frames; f++ {
+		frame := transitionFrame(from, img, t, float64(f)/float64(frames))
+		if err := d.writeImage(index, d.keyImage(index, frame)); err != nil {
+			return err
+		}
+
+		time.Sleep(delay)
+	}
+
+	return d.SetImage(index, img)
+}
+
+// transitionFrame returns the frame of a transition between two images of the
+// same size at the given progress, between 0 and 1.
+func transitionFrame(from, to image.Image, t Transition, progress float64) image.Image {
+	b := image.Rect(0, 0, to.Bounds().Dx(), to.Bounds().Dy())
+	frame := image.NewRGBA(b)
+
+	var dirX, dirY int
+	switch t {
+	case TransitionCrossfade:
+		src, dst := toRGBA(from), toRGBA(to)
+		for y := 0; y < b.Dy(); y++ {
+			for x := 0; x < b.Dx(); x++ {
+				c1 := src.RGBAAt(src.Bounds().Min.X+x, src.Bounds().Min.Y+y)
+				c2 := dst.RGBAAt(dst.Bounds().Min.X+x, dst.Bounds().Min.Y+y)
+				frame.SetRGBA(x, y, color.RGBA{
+					lerp(c1.R, c2.R, progress),
+					lerp(c1.G, c2.G, progress),
+					lerp(c1.B, c2.B, progress),
+					lerp(c1.A, c2.A, progress),
+				})
+			}
+		}
+		return frame
+
+	case TransitionSlideLeft:
+		dirX = -1
+	case TransitionSlideRight:
+		dirX = 1
+	case TransitionSlideUp:
+		dirY = -1
+	case TransitionSlideDown:
+		dirY = 1
+	}
+
+	// the current image moves out in the slide direction, the new image
+	// follows it from the opposite edge
+	offset := image.Pt(dirX*int(float64(b.Dx())*progress), dirY*int(float64(b.Dy())*progress))
+	follow := offset.Sub(image.Pt(dirX*b.Dx(), dirY*b.Dy()))
+	draw.Draw(frame, b.Add(offset), from, from.Bounds().Min, draw.Src)
+	draw.Draw(frame, b.Add(follow), to, to.Bounds().Min, draw.Src)
+	return frame
+}
+
+// lerp linearly interpolates between two color channel values.
+func lerp(a, b uint8, progress float64) uint8 {
+	return uint8(float64(a) + (float64(b)-float64(a))*progress + 0.5)
+}